@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"github.com/xiyuan/compliance-mcp/pkg/config"
+)
+
+// Exit codes of check mode
+const (
+	checkPassed = 0
+	// checkFailed means more high-severity checks failed than allowed
+	checkFailed = 1
+	// checkBroken means the suite could not be collected, has scans that are
+	// not done or have no results, or the report could not be written
+	checkBroken = 2
+)
+
+// checkOptions configures check mode, which collects one suite, writes a
+// JUnit report and exits instead of serving MCP
+type checkOptions struct {
+	suite           string
+	cluster         string
+	output          string
+	maxHighFailures int
+	countErrors     bool
+}
+
+// bindCheckFlags registers the check mode flags. They select a mode of the
+// binary rather than configure the server, so they have no configuration
+// file equivalent.
+func bindCheckFlags(fs *flag.FlagSet, opts *checkOptions) {
+	fs.StringVar(&opts.suite, "check-suite", "", "Collect this ComplianceSuite, write a JUnit report and exit instead of serving; exits 1 when high-severity failures exceed --max-high-failures and 2 when the suite has no complete results or on errors")
+	fs.StringVar(&opts.cluster, "check-cluster", "", "Cluster to check (default: the default cluster)")
+	fs.StringVar(&opts.output, "junit-output", "-", "File to write the JUnit report to in check mode, - for stdout")
+	fs.IntVar(&opts.maxHighFailures, "max-high-failures", 0, "Number of failed high-severity checks tolerated in check mode")
+	fs.BoolVar(&opts.countErrors, "count-errors", true, "Count high-severity ERROR and INCONSISTENT checks as failures in check mode")
+}
+
+// runCheck collects a suite, writes its JUnit report and returns the exit
+// code
+func runCheck(cfg *config.Config, opts checkOptions) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if timeout := cfg.Server.ToolTimeout.Duration; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cluster, err := checkCluster(cfg.Clusters, opts.cluster)
+	if err != nil {
+		log.Printf("Check failed: %v", err)
+		return checkBroken
+	}
+
+	client, err := compliance.NewComplianceClientForConfig(cluster.Config, cfg.Compliance.Namespace)
+	if err != nil {
+		log.Printf("Check failed: %v", err)
+		return checkBroken
+	}
+
+	data, err := client.CollectExport(ctx, cluster.Name, opts.suite, "")
+	if err != nil {
+		log.Printf("Check failed: %v", err)
+		return checkBroken
+	}
+
+	incomplete := incompleteScans(data)
+	for _, problem := range incomplete {
+		log.Printf("ERROR: %s", problem)
+	}
+
+	report := compliance.NewJUnitReport(data)
+	out, err := report.Marshal()
+	if err != nil {
+		log.Printf("Check failed: %v", err)
+		return checkBroken
+	}
+
+	if opts.output == "-" {
+		_, err = os.Stdout.Write(out)
+	} else {
+		err = os.WriteFile(opts.output, out, 0o644)
+	}
+	if err != nil {
+		log.Printf("Check failed: failed to write JUnit report: %v", err)
+		return checkBroken
+	}
+
+	if len(incomplete) > 0 {
+		log.Printf("Check failed: suite %s has no complete results", opts.suite)
+		return checkBroken
+	}
+
+	log.Printf("Suite %s in cluster %s: %d checks in %d scans, %d failures (%d high severity), %d errors, %d skipped",
+		opts.suite, cluster.Name, report.Tests, len(report.Suites), report.Failures, compliance.CountFailures(data, "high", false), report.Errors, report.Skipped)
+
+	high := compliance.CountFailures(data, "high", opts.countErrors)
+	if high > opts.maxHighFailures {
+		what := "failed"
+		if opts.countErrors {
+			what = "failed or errored"
+		}
+		log.Printf("FAILED: %d high-severity checks %s, at most %d allowed", high, what, opts.maxHighFailures)
+		return checkFailed
+	}
+	log.Printf("PASSED")
+	return checkPassed
+}
+
+// incompleteScans describes why the suite's results cannot be trusted to
+// pass: it has no scans, or scans that are not done or have no results
+func incompleteScans(data *compliance.ExportData) []string {
+	if len(data.Scans) == 0 {
+		return []string{fmt.Sprintf("suite %s has no scans", data.Suite)}
+	}
+
+	var problems []string
+	for _, export := range data.Scans {
+		switch {
+		case export.Scan.Status.Phase != compliance.PhaseDone:
+			problems = append(problems, fmt.Sprintf("scan %s is %s, not %s", export.Scan.Name, export.Scan.Status.Phase, compliance.PhaseDone))
+		case len(export.Results) == 0:
+			problems = append(problems, fmt.Sprintf("scan %s has no check results", export.Scan.Name))
+		}
+	}
+	return problems
+}
+
+// checkCluster picks the cluster to check: the named one, else the
+// configured default, else the first cluster, as the server would
+func checkCluster(cfg config.ClustersConfig, name string) (compliance.Cluster, error) {
+	clusters, err := buildClusters(cfg)
+	if err != nil {
+		return compliance.Cluster{}, fmt.Errorf("failed to load clusters: %w", err)
+	}
+
+	if len(clusters) == 0 {
+		if name != "" && name != compliance.DefaultClusterName {
+			return compliance.Cluster{}, fmt.Errorf("unknown cluster %q: no cluster registry is configured", name)
+		}
+		restConfig, err := compliance.GetKubeConfig()
+		if err != nil {
+			return compliance.Cluster{}, fmt.Errorf("failed to get kubeconfig: %w", err)
+		}
+		return compliance.Cluster{Name: compliance.DefaultClusterName, Config: restConfig}, nil
+	}
+
+	if name == "" {
+		name = cfg.Default
+	}
+	if name == "" {
+		return clusters[0], nil
+	}
+
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name == name {
+			return cluster, nil
+		}
+		names = append(names, cluster.Name)
+	}
+	return compliance.Cluster{}, fmt.Errorf("unknown cluster %q (configured: %s)", name, strings.Join(names, ", "))
+}
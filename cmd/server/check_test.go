@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIncompleteScans(t *testing.T) {
+	scan := func(name string, phase compliance.ComplianceScanPhase, results int) compliance.ScanExport {
+		return compliance.ScanExport{
+			Scan: compliance.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     compliance.ComplianceScanStatus{Phase: phase},
+			},
+			Results: make([]compliance.ComplianceCheckResult, results),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		scans []compliance.ScanExport
+		want  []string
+	}{
+		{
+			name:  "done scans with results",
+			scans: []compliance.ScanExport{scan("ocp4-cis", compliance.PhaseDone, 3), scan("ocp4-cis-node-worker", compliance.PhaseDone, 1)},
+		},
+		{
+			name: "no scans",
+			want: []string{"suite cis-compliance has no scans"},
+		},
+		{
+			name:  "scan still running",
+			scans: []compliance.ScanExport{scan("ocp4-cis", compliance.PhaseDone, 3), scan("ocp4-cis-node-worker", compliance.PhaseRunning, 1)},
+			want:  []string{"scan ocp4-cis-node-worker is RUNNING, not DONE"},
+		},
+		{
+			name:  "scan without results",
+			scans: []compliance.ScanExport{scan("ocp4-cis", compliance.PhaseDone, 0)},
+			want:  []string{"scan ocp4-cis has no check results"},
+		},
+		{
+			name:  "every scan incomplete",
+			scans: []compliance.ScanExport{scan("ocp4-cis", compliance.PhaseAggregating, 0), scan("ocp4-cis-node-worker", compliance.PhaseDone, 0)},
+			want:  []string{"scan ocp4-cis is AGGREGATING, not DONE", "scan ocp4-cis-node-worker has no check results"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &compliance.ExportData{Suite: "cis-compliance", Scans: tt.scans}
+			if got := incompleteScans(data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("incompleteScans() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
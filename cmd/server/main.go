@@ -1,48 +1,389 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/audit"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"github.com/xiyuan/compliance-mcp/pkg/config"
+	"github.com/xiyuan/compliance-mcp/pkg/health"
 	"github.com/xiyuan/compliance-mcp/pkg/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/metrics"
+	"github.com/xiyuan/compliance-mcp/pkg/tlsutil"
+	"k8s.io/client-go/kubernetes"
 )
 
+// probeTimeout bounds each liveness and readiness probe
+const probeTimeout = 5 * time.Second
+
+// watcherResync is how often the readiness informers resync
+const watcherResync = 10 * time.Minute
+
 func main() {
-	// Get configuration from environment
-	namespace := os.Getenv("COMPLIANCE_NAMESPACE")
-	if namespace == "" {
-		namespace = "openshift-compliance"
+	// The configuration file supplies the flag defaults, so it is loaded
+	// before the flags are parsed
+	cfg, err := config.Load(config.ConfigPath(os.Args[1:]))
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	cfg.ApplyEnv()
+	cfg.BindFlags(flag.CommandLine)
+	var check checkOptions
+	bindCheckFlags(flag.CommandLine, &check)
+	flag.Parse()
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// In stdio mode stdout carries the protocol stream, so all logging
+	// must go to stderr
+	log.SetOutput(os.Stderr)
+
+	if check.suite != "" {
+		os.Exit(runCheck(cfg, check))
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8350"
+	if err := run(cfg); err != nil {
+		log.Fatalf("%v", err)
 	}
+	log.Printf("Server stopped")
+}
+
+// run serves MCP until SIGTERM or SIGINT, then shuts down gracefully. It
+// returns instead of exiting so deferred cleanup, such as flushing the audit
+// log, always happens.
+func run(cfg *config.Config) error {
+	var err error
+	namespace := cfg.Compliance.Namespace
+	transport := cfg.Server.Transport
 
 	log.Printf("Starting Compliance MCP Server...")
 	log.Printf("Namespace: %s", namespace)
-	log.Printf("Port: %s", port)
+	log.Printf("Transport: %s", transport)
+
+	// Authentication only applies to the HTTP transports
+	var authenticator auth.Authenticator
+	if transport != config.TransportStdio {
+		authenticator, err = buildAuthenticator(cfg.Auth)
+		if err != nil {
+			return fmt.Errorf("failed to configure authentication: %w", err)
+		}
+		if authenticator == nil {
+			log.Printf("WARNING: authentication is disabled, anyone who can reach the server can call its tools")
+		}
+	}
+
+	// TLS only applies to the HTTP transports
+	var tlsConfig *tls.Config
+	if cfg.Server.TLS.CertFile != "" {
+		tlsConfig, err = tlsutil.NewServerTLSConfig(tlsutil.Config{
+			CertFile:     cfg.Server.TLS.CertFile,
+			KeyFile:      cfg.Server.TLS.KeyFile,
+			MinVersion:   cfg.Server.TLS.MinVersion,
+			ClientCAFile: cfg.Server.TLS.ClientCAFile,
+			ClientAuth:   cfg.Server.TLS.ClientAuth,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+	}
+
+	clusters, err := buildClusters(cfg.Clusters)
+	if err != nil {
+		return fmt.Errorf("failed to load clusters: %w", err)
+	}
+	if len(clusters) > 0 {
+		log.Printf("Clusters: %d configured", len(clusters))
+	}
+
+	opts := []mcp.Option{
+		mcp.WithClusters(clusters, cfg.Clusters.Default),
+		mcp.WithDefaultToolTimeout(cfg.Server.ToolTimeout.Duration),
+		mcp.WithResponseBudget(cfg.Server.ResponseBudgetBytes),
+		mcp.WithRateLimit(cfg.Server.RateLimit.RequestsPerSecond, cfg.Server.RateLimit.Burst),
+		mcp.WithHeavyToolConcurrency(cfg.Server.MaxConcurrentHeavyTools),
+		mcp.WithImpersonation(cfg.Auth.Impersonate),
+		mcp.WithAllowedNamespaces(cfg.Compliance.AllowedNamespaces),
+		mcp.WithToolPolicy(buildToolPolicy(cfg.Tools)),
+		mcp.WithThresholds(compliance.Thresholds{
+			StuckRunningAfter:   cfg.Analyzer.StuckRunningAfter.Duration,
+			StuckLaunchingAfter: cfg.Analyzer.StuckLaunchingAfter.Duration,
+			RestartWarning:      cfg.Analyzer.RestartWarning,
+		}),
+		mcp.WithLogLimits(mcp.LogLimits{
+			DefaultTailLines: cfg.Logs.DefaultTailLines,
+			MaxTailLines:     cfg.Logs.MaxTailLines,
+			MaxFindings:      cfg.Logs.MaxFindings,
+		}),
+		mcp.WithRawResultsExtractor(compliance.RawResultsExtractor{
+			Image:   cfg.RawResults.ExtractorImage,
+			Timeout: cfg.RawResults.ExtractorTimeout.Duration,
+		}),
+	}
+	for tool, timeout := range cfg.Server.ToolTimeouts {
+		opts = append(opts, mcp.WithToolTimeout(tool, timeout.Duration))
+	}
+	if cfg.Audit.Log != "" {
+		auditLogger, err := buildAuditLogger(cfg.Audit)
+		if err != nil {
+			return fmt.Errorf("failed to configure audit log: %w", err)
+		}
+		// Deferred first so it runs last, after every call has been recorded
+		defer func() {
+			if err := auditLogger.Close(); err != nil {
+				log.Printf("Failed to close audit log: %v", err)
+			}
+		}()
+		opts = append(opts, mcp.WithAuditLogger(auditLogger))
+		log.Printf("Audit log: %s", cfg.Audit.Log)
+	}
+
+	// Metrics are served next to the MCP endpoint, so only over HTTP
+	var serverMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled && transport != config.TransportStdio {
+		serverMetrics = metrics.New()
+		opts = append(opts, mcp.WithMetrics(serverMetrics, cfg.Metrics.PostureInterval.Duration))
+	}
 
 	// Create MCP server
-	mcpServer, err := mcp.NewMCPServer(namespace)
+	mcpServer, err := mcp.NewMCPServer(namespace, opts...)
 	if err != nil {
-		log.Fatalf("Failed to create MCP server: %v", err)
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if transport == config.TransportStdio {
+		return serveStdio(ctx, mcpServer, cfg.Server.ShutdownTimeout.Duration)
 	}
 
-	// Create HTTP handler
-	mcpHandler := server.NewStreamableHTTPServer(mcpServer.GetServer())
+	// The watcher's informers back the readiness probe
+	watcher := mcpServer.Client().NewWatcher(watcherResync)
+	watcher.Start()
+	defer watcher.Stop()
 
-	// Set up HTTP server
-	http.Handle("/mcp", mcpHandler)
+	// Posture gauges are collected in the background, not on scrape
+	go mcpServer.RunPostureMetrics(ctx)
 
-	// Add health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "OK")
-	})
+	return serveHTTP(ctx, cfg, mcpServer, authenticator, tlsConfig, serverMetrics, watcher)
+}
+
+// serveStdio serves the MCP server over stdin/stdout until ctx is done or
+// stdin is closed, then drains running tool calls
+func serveStdio(ctx context.Context, mcpServer *mcp.MCPServer, shutdownTimeout time.Duration) error {
+	stdioServer := server.NewStdioServer(mcpServer.GetServer())
+	stdioServer.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	// Tool calls run under the listen context, so it is only cancelled
+	// once they have drained
+	listenCtx, cancelListen := context.WithCancel(context.Background())
+	defer cancelListen()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- stdioServer.Listen(listenCtx, os.Stdin, os.Stdout)
+	}()
+
+	// Closing stdin stops reading requests, but calls already running
+	// still drain as they do on SIGTERM
+	var listenErr error
+	listening := true
+	select {
+	case listenErr = <-errCh:
+		listening = false
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for running tool calls", shutdownTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := mcpServer.Drain(drainCtx); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
+	cancelListen()
+	if listening {
+		listenErr = <-errCh
+	}
+	if listenErr != nil && !errors.Is(listenErr, context.Canceled) && !errors.Is(listenErr, io.EOF) {
+		return fmt.Errorf("server failed: %w", listenErr)
+	}
+	return nil
+}
+
+// buildClusters loads the cluster registry. It returns no clusters when
+// none are configured, so the server falls back to its single kubeconfig.
+func buildClusters(cfg config.ClustersConfig) ([]compliance.Cluster, error) {
+	var clusters []compliance.Cluster
+
+	if cfg.Kubeconfig != "" {
+		contexts, err := compliance.LoadKubeconfigContexts(cfg.Kubeconfig, cfg.Contexts)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, contexts...)
+	}
+
+	if cfg.Directory != "" {
+		files, err := compliance.LoadKubeconfigDirectory(cfg.Directory)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, files...)
+	}
+
+	return clusters, nil
+}
+
+// buildToolPolicy converts the tools configuration into the server's policy
+func buildToolPolicy(cfg config.ToolsConfig) mcp.ToolPolicy {
+	policy := mcp.ToolPolicy{
+		Enabled:  cfg.Enabled,
+		Disabled: cfg.Disabled,
+		ReadOnly: cfg.ReadOnly,
+		// Callers in none of the groups get no tools unless the policy
+		// says otherwise
+		DenyUnmatched: cfg.DefaultGroupPolicy == config.GroupPolicyDeny,
+	}
+	for _, group := range cfg.Groups {
+		policy.Groups = append(policy.Groups, mcp.GroupToolRule{
+			Group: group.Group,
+			Allow: group.Allow,
+			Deny:  group.Deny,
+		})
+	}
+	if !cfg.ReadOnly {
+		log.Printf("WARNING: read-only mode is off, tools that modify the cluster may be offered")
+	}
+	return policy
+}
+
+// buildAuthenticator creates the authenticator for the MCP endpoints, or
+// nil if no authentication method is configured
+func buildAuthenticator(cfg config.AuthConfig) (auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+
+	if cfg.TokenFile != "" {
+		static, err := auth.NewStaticTokenAuthenticator(cfg.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, static)
+		log.Printf("Authentication: static tokens from %s", cfg.TokenFile)
+	}
+
+	if cfg.TokenReview {
+		config, err := compliance.GetKubeConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kubeconfig for TokenReview: %w", err)
+		}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client for TokenReview: %w", err)
+		}
+
+		authenticators = append(authenticators, auth.NewTokenReviewAuthenticator(kubeClient, cfg.Audiences))
+		log.Printf("Authentication: Kubernetes TokenReview")
+	}
+
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+	return auth.NewUnionAuthenticator(authenticators...), nil
+}
+
+// serveHTTP serves the MCP server over streamable HTTP or SSE along with
+// the health, metrics and info endpoints until ctx is done, then shuts down
+// gracefully
+func serveHTTP(ctx context.Context, cfg *config.Config, mcpServer *mcp.MCPServer, authenticator auth.Authenticator, tlsConfig *tls.Config, serverMetrics *metrics.Metrics, watcher *compliance.Watcher) error {
+	transport := cfg.Server.Transport
+	namespace := cfg.Compliance.Namespace
+	port := cfg.Server.Port
+	log.Printf("Port: %s", port)
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	// Reject unauthenticated requests before they reach the MCP handlers.
+	// With clientAuth require, the TLS handshake still admits clients
+	// without a certificate so the kubelet's probes work; the protected
+	// endpoints turn them away here.
+	requireClientCert := tlsConfig != nil && tlsutil.Config{
+		ClientCAFile: cfg.Server.TLS.ClientCAFile,
+		ClientAuth:   cfg.Server.TLS.ClientAuth,
+	}.ClientCertRequired()
+	protect := func(handler http.Handler) http.Handler {
+		if authenticator != nil {
+			handler = auth.Middleware(authenticator, handler)
+		}
+		if requireClientCert {
+			handler = tlsutil.RequireVerifiedClientCert(handler)
+		}
+		return handler
+	}
+
+	// Create MCP handler for the selected transport
+	var endpoints []string
+	switch transport {
+	case config.TransportSSE:
+		sseServer := server.NewSSEServer(mcpServer.GetServer())
+		http.Handle("/sse", protect(sseServer.SSEHandler()))
+		http.Handle("/message", protect(sseServer.MessageHandler()))
+		endpoints = []string{"/sse", "/message"}
+	default:
+		http.Handle("/mcp", protect(server.NewStreamableHTTPServer(mcpServer.GetServer())))
+		endpoints = []string{"/mcp"}
+	}
+	mcpEndpoint := endpoints[0]
+
+	// The HTML report shows compliance data, so it needs the same
+	// credentials as the MCP endpoint
+	http.Handle("GET /report/{suite}", protect(mcpServer.ReportHandler()))
+
+	// Liveness only reflects the process, so a cluster outage doesn't get
+	// the pod restarted; readiness checks everything the tools depend on.
+	// /health is kept as an alias of /livez for existing deployments.
+	client := mcpServer.Client()
+	livez := health.Handler(probeTimeout)
+	readyz := health.Handler(probeTimeout,
+		health.Check{Name: "apiserver", Run: client.CheckAPIServer},
+		health.Check{Name: "compliance-api", Run: client.CheckComplianceAPI},
+		health.Check{Name: "informers", Run: func(context.Context) error { return watcher.CheckSynced() }},
+		health.Check{Name: "shutdown", Run: func(context.Context) error {
+			if mcpServer.Draining() {
+				return fmt.Errorf("server is shutting down")
+			}
+			return nil
+		}},
+	)
+	http.Handle("/livez", livez)
+	http.Handle("/health", livez)
+	http.Handle("/readyz", readyz)
+
+	// The posture gauges show compliance data, so scrapes need the same
+	// credentials as the MCP endpoint
+	if serverMetrics != nil {
+		http.Handle("/metrics", protect(serverMetrics.Handler()))
+	}
 
 	// Add root handler with info
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -64,8 +405,9 @@ func main() {
     <div class="info">
         <p><strong>Status:</strong> Running</p>
         <p><strong>Namespace:</strong> %s</p>
-        <p><strong>MCP Endpoint:</strong> <code>http://localhost:%s/mcp</code></p>
-        <p><strong>Health Check:</strong> <code>http://localhost:%s/health</code></p>
+        <p><strong>Transport:</strong> %s</p>
+        <p><strong>MCP Endpoint:</strong> <code>%s://localhost:%s%s</code></p>
+        <p><strong>Health Checks:</strong> <code>%s://localhost:%s/livez</code>, <code>/readyz</code></p>
     </div>
     <h2>Available Tools</h2>
     <ul>
@@ -75,22 +417,99 @@ func main() {
         <li><strong>compliance_remediations</strong> - Get available remediations</li>
         <li><strong>compliance_logs</strong> - Fetch and analyze pod logs</li>
         <li><strong>compliance_diagnose</strong> - Auto-detect common issues</li>
+        <li><strong>compliance_clusters</strong> - List configured clusters and their operator versions</li>
+        <li><strong>compliance_fleet_summary</strong> - Roll up compliance across all clusters</li>
+        <li><strong>compliance_xccdf_export</strong> - Export scan results as XCCDF XML</li>
+        <li><strong>compliance_raw_results</strong> - Read raw OpenSCAP results and rule evidence</li>
+        <li><strong>compliance_extract_raw_results</strong> - Read raw results from the raw results PVC (write)</li>
+        <li><strong>compliance_export</strong> - Export suite results as OSCAL, XCCDF, SARIF, CSV, TSV or JUnit</li>
+        <li><strong>compliance_report</strong> - HTML compliance report for a suite, also served at <code>/report/{suite}</code></li>
+        <li><strong>compliance_controls</strong> - Per-control status for NIST 800-53, CIS or PCI-DSS with contributing rules</li>
     </ul>
     <h2>Usage</h2>
-    <p>Configure your MCP client to connect to this server at <code>http://localhost:%s/mcp</code></p>
+    <p>Configure your MCP client to connect to this server at <code>%s://localhost:%s%s</code></p>
     <p>See the <a href="https://github.com/xiyuan/compliance-mcp">documentation</a> for more information.</p>
 </body>
 </html>
-`, namespace, port, port, port)
+`, namespace, transport, scheme, port, mcpEndpoint, scheme, port, scheme, port, mcpEndpoint)
 	})
 
 	// Start server
 	addr := fmt.Sprintf(":%s", port)
 	log.Printf("Server listening on %s", addr)
-	log.Printf("MCP endpoint available at http://localhost%s/mcp", addr)
-	log.Printf("Health check available at http://localhost%s/health", addr)
+	for _, endpoint := range endpoints {
+		log.Printf("MCP endpoint available at %s://localhost%s%s", scheme, addr, endpoint)
+	}
+	log.Printf("Probes available at %s://localhost%s/livez and /readyz", scheme, addr)
+	log.Printf("HTML reports available at %s://localhost%s/report/{suite}", scheme, addr)
+	if serverMetrics != nil {
+		log.Printf("Metrics available at %s://localhost%s/metrics", scheme, addr)
+	}
+
+	// Requests, including long-lived SSE and streaming connections, run
+	// under a base context that is cancelled once tool calls have drained
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	httpServer := &http.Server{
+		Addr:        addr,
+		TLSConfig:   tlsConfig,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			// Certificates come from TLSConfig.GetCertificate
+			errCh <- httpServer.ListenAndServeTLS("", "")
+		} else {
+			errCh <- httpServer.ListenAndServe()
+		}
+	}()
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
 	}
+
+	shutdownTimeout := cfg.Server.ShutdownTimeout.Duration
+	log.Printf("Shutting down, waiting up to %s for running tool calls", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections while running calls finish. Their
+	// responses still reach clients over the open connections.
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := mcpServer.Drain(shutdownCtx); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
+	// End the streams that would otherwise keep connections open
+	cancelBase()
+
+	if err := <-shutdownErr; err != nil {
+		log.Printf("WARNING: connections still open after %s, closing them: %v", shutdownTimeout, err)
+		httpServer.Close()
+	}
+	return nil
+}
+
+// buildAuditLogger creates the audit logger with the default redaction
+// rules plus any extra argument name patterns
+func buildAuditLogger(cfg config.AuditConfig) (*audit.Logger, error) {
+	var extraKeys []*regexp.Regexp
+	for _, pattern := range cfg.Redact {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		extraKeys = append(extraKeys, re)
+	}
+
+	return audit.NewLogger(cfg.Log, cfg.MaxSizeMB*1024*1024, cfg.MaxBackups, audit.NewRedactor(extraKeys))
 }
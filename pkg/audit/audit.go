@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event records a single tool invocation
+type Event struct {
+	Time        time.Time              `json:"time"`
+	User        string                 `json:"user"`
+	Groups      []string               `json:"groups,omitempty"`
+	SessionID   string                 `json:"sessionId,omitempty"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	DurationMs  int64                  `json:"durationMs"`
+	ResultBytes int                    `json:"resultBytes"`
+	IsError     bool                   `json:"isError"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// Logger writes audit events as JSON lines
+type Logger struct {
+	redactor *Redactor
+
+	mu     sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewLogger creates an audit logger writing to destination, which is
+// "stdout", "stderr" or a file path. Files are rotated once they reach
+// maxSizeBytes, keeping maxBackups old files.
+func NewLogger(destination string, maxSizeBytes int64, maxBackups int, redactor *Redactor) (*Logger, error) {
+	if redactor == nil {
+		redactor = NewRedactor(nil)
+	}
+
+	logger := &Logger{redactor: redactor}
+
+	switch destination {
+	case "":
+		return nil, fmt.Errorf("audit log destination is required")
+	case "stdout":
+		logger.writer = os.Stdout
+	case "stderr":
+		logger.writer = os.Stderr
+	default:
+		file, err := NewRotatingFile(destination, maxSizeBytes, maxBackups)
+		if err != nil {
+			return nil, err
+		}
+		logger.writer = file
+		logger.closer = file
+	}
+
+	return logger, nil
+}
+
+// Record redacts and writes an audit event
+func (l *Logger) Record(event Event) error {
+	event.Arguments = l.redactor.Redact(event.Arguments)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.writer.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the audit log
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closer != nil {
+		return l.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewLogger(path, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	events := []Event{
+		{
+			Time:      time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+			User:      "alice",
+			Tool:      "compliance_check_results",
+			Arguments: map[string]interface{}{"scan_name": "ocp4-cis", "token": "abc"},
+		},
+		{
+			Time:    time.Date(2026, 10, 1, 10, 0, 1, 0, time.UTC),
+			User:    "bob",
+			Tool:    "compliance_suite_status",
+			IsError: true,
+			Error:   "suite not found",
+		},
+	}
+	for _, event := range events {
+		if err := logger.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) != len(events) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(events), content)
+	}
+
+	var first Event
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("line 1 is not an event: %v", err)
+	}
+	wantArguments := map[string]interface{}{"scan_name": "ocp4-cis", "token": redactedValue}
+	if !reflect.DeepEqual(first.Arguments, wantArguments) {
+		t.Errorf("arguments = %v, want %v", first.Arguments, wantArguments)
+	}
+	if !strings.Contains(lines[1], `"isError":true`) || !strings.Contains(lines[1], `"error":"suite not found"`) {
+		t.Errorf("line 2 = %s, want the error recorded", lines[1])
+	}
+}
+
+func TestNewLoggerDestination(t *testing.T) {
+	if _, err := NewLogger("", 0, 0, nil); err == nil {
+		t.Errorf("NewLogger(\"\") succeeded, want an error")
+	}
+	if _, err := NewLogger(filepath.Join(t.TempDir(), "missing", "audit.log"), 0, 0, nil); err == nil {
+		t.Errorf("NewLogger() in a missing directory succeeded, want an error")
+	}
+
+	logger, err := NewLogger("stderr", 0, 0, nil)
+	if err != nil {
+		t.Fatalf("NewLogger(stderr) error = %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
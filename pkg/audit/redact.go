@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"regexp"
+)
+
+// redactedValue replaces redacted argument values
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys matches argument names whose values are never logged
+var DefaultRedactedKeys = []string{
+	`(?i)token`,
+	`(?i)password`,
+	`(?i)passwd`,
+	`(?i)secret`,
+	`(?i)credential`,
+	`(?i)api[_-]?key`,
+	`(?i)private[_-]?key`,
+	`(?i)authorization`,
+}
+
+// secretValuePatterns match values that look like credentials regardless of
+// the argument name
+var secretValuePatterns = []*regexp.Regexp{
+	// JSON Web Tokens, including Kubernetes ServiceAccount tokens
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	// Bearer authorization values
+	regexp.MustCompile(`(?i)bearer\s+\S+`),
+	// PEM private keys
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+}
+
+// Redactor removes secret-like values from tool arguments
+type Redactor struct {
+	keys []*regexp.Regexp
+}
+
+// NewRedactor creates a redactor for argument names matching any of the
+// given patterns in addition to DefaultRedactedKeys
+func NewRedactor(extraKeys []*regexp.Regexp) *Redactor {
+	keys := make([]*regexp.Regexp, 0, len(DefaultRedactedKeys)+len(extraKeys))
+	for _, pattern := range DefaultRedactedKeys {
+		keys = append(keys, regexp.MustCompile(pattern))
+	}
+	keys = append(keys, extraKeys...)
+
+	return &Redactor{keys: keys}
+}
+
+// Redact returns a copy of arguments with secret-like values replaced
+func (r *Redactor) Redact(arguments map[string]interface{}) map[string]interface{} {
+	if arguments == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(arguments))
+	for key, value := range arguments {
+		if r.isSecretKey(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = r.redactValue(value)
+	}
+
+	return redacted
+}
+
+// redactValue redacts nested maps and lists and secret-looking strings
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.Redact(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	case string:
+		for _, pattern := range secretValuePatterns {
+			if pattern.MatchString(v) {
+				return redactedValue
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) isSecretKey(key string) bool {
+	for _, pattern := range r.keys {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
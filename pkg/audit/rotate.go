@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only file that is rotated once it reaches a
+// maximum size. Old files are kept as path.1 (newest) to path.N (oldest).
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile opens path for appending. A maxSize of zero disables
+// rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer, rotating the file first if p would take it
+// past the maximum size
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close syncs and closes the current file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	syncErr := r.file.Sync()
+	closeErr := r.file.Close()
+	r.file = nil
+
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", r.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log %s: %w", r.path, err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups along, moves the current file to path.1 and
+// opens a fresh file. Callers must hold r.mu.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log for rotation: %w", err)
+	}
+
+	var rotateErr error
+	if r.maxBackups > 0 {
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			from := fmt.Sprintf("%s.%d", r.path, i)
+			if _, err := os.Stat(from); err == nil {
+				os.Rename(from, fmt.Sprintf("%s.%d", r.path, i+1))
+			}
+		}
+		rotateErr = os.Rename(r.path, r.path+".1")
+	} else {
+		rotateErr = os.Remove(r.path)
+	}
+
+	// Reopen even if rotation failed so later events are still written
+	if err := r.open(); err != nil {
+		return err
+	}
+	if rotateErr != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", rotateErr)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSize    int64
+		maxBackups int
+		writes     []string
+		// want maps each file name to its content; files not listed must not
+		// exist
+		want map[string]string
+	}{
+		{
+			name:       "no rotation under the limit",
+			maxSize:    100,
+			maxBackups: 2,
+			writes:     []string{"one\n", "two\n"},
+			want:       map[string]string{"audit.log": "one\ntwo\n"},
+		},
+		{
+			name:       "rotation disabled",
+			maxSize:    0,
+			maxBackups: 2,
+			writes:     []string{"one\n", "two\n", "three\n"},
+			want:       map[string]string{"audit.log": "one\ntwo\nthree\n"},
+		},
+		{
+			name:       "backups shift and the oldest is dropped",
+			maxSize:    8,
+			maxBackups: 2,
+			writes:     []string{"one\n", "two\n", "three\n", "four\n", "five\n"},
+			want: map[string]string{
+				"audit.log":   "five\n",
+				"audit.log.1": "four\n",
+				"audit.log.2": "three\n",
+			},
+		},
+		{
+			name:       "no backups",
+			maxSize:    8,
+			maxBackups: 0,
+			writes:     []string{"one\n", "two\n", "three\n"},
+			want:       map[string]string{"audit.log": "three\n"},
+		},
+		{
+			name:       "an oversized event is written whole",
+			maxSize:    4,
+			maxBackups: 1,
+			writes:     []string{"a long event\n"},
+			want:       map[string]string{"audit.log": "a long event\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file, err := NewRotatingFile(filepath.Join(dir, "audit.log"), tt.maxSize, tt.maxBackups)
+			if err != nil {
+				t.Fatalf("NewRotatingFile() error = %v", err)
+			}
+			for _, write := range tt.writes {
+				if _, err := file.Write([]byte(write)); err != nil {
+					t.Fatalf("Write(%q) error = %v", write, err)
+				}
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := readDir(t, dir)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing size counts towards the limit
+	file, err := NewRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() error = %v", err)
+	}
+	for _, write := range []string{"new\n", "next\n"} {
+		if _, err := file.Write([]byte(write)); err != nil {
+			t.Fatalf("Write(%q) error = %v", write, err)
+		}
+	}
+	file.Close()
+
+	want := map[string]string{"audit.log": "next\n", "audit.log.1": "old\nnew\n"}
+	if got := readDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+}
+
+// readDir returns the name and content of every file in dir
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(content)
+	}
+	return files
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnauthenticated is returned when a token is not recognised
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// UserInfo identifies an authenticated caller
+type UserInfo struct {
+	Name   string
+	UID    string
+	Groups []string
+}
+
+// Authenticator validates a bearer token and returns the caller it belongs to
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*UserInfo, error)
+}
+
+// unionAuthenticator tries each authenticator in turn
+type unionAuthenticator []Authenticator
+
+// NewUnionAuthenticator returns an authenticator that accepts a token if
+// any of the given authenticators accepts it
+func NewUnionAuthenticator(authenticators ...Authenticator) Authenticator {
+	if len(authenticators) == 1 {
+		return authenticators[0]
+	}
+	return unionAuthenticator(authenticators)
+}
+
+// AuthenticateToken implements Authenticator
+func (u unionAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	var errs []string
+	for _, authenticator := range u {
+		user, err := authenticator.AuthenticateToken(ctx, token)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrUnauthenticated) {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("authentication failed: %s", strings.Join(errs, "; "))
+	}
+	return nil, ErrUnauthenticated
+}
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated caller
+func WithUser(ctx context.Context, user *UserInfo) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the authenticated caller, if any
+func UserFromContext(ctx context.Context) (*UserInfo, bool) {
+	user, ok := ctx.Value(userKey{}).(*UserInfo)
+	return user, ok && user != nil
+}
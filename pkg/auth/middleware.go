@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Middleware rejects requests without a valid bearer token before they
+// reach next. The authenticated caller is stored in the request context.
+func Middleware(authenticator Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, "missing bearer token")
+			return
+		}
+
+		user, err := authenticator.AuthenticateToken(r.Context(), token)
+		if err != nil {
+			if !errors.Is(err, ErrUnauthenticated) {
+				log.Printf("Authentication error from %s: %v", r.RemoteAddr, err)
+			}
+			unauthorized(w, "invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// bearerToken extracts the token from the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="compliance-mcp"`)
+	http.Error(w, message, http.StatusUnauthorized)
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authenticatorFunc adapts a function to Authenticator
+type authenticatorFunc func(ctx context.Context, token string) (*UserInfo, error)
+
+func (f authenticatorFunc) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	return f(ctx, token)
+}
+
+func TestMiddleware(t *testing.T) {
+	authenticator := authenticatorFunc(func(ctx context.Context, token string) (*UserInfo, error) {
+		switch token {
+		case "good":
+			return &UserInfo{Name: "alice"}, nil
+		case "broken":
+			return nil, errors.New("token review failed")
+		default:
+			return nil, ErrUnauthenticated
+		}
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{name: "valid token", authorization: "Bearer good", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "scheme is case insensitive", authorization: "bearer  good ", wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "missing header", wantStatus: http.StatusUnauthorized},
+		{name: "basic auth", authorization: "Basic Z29vZA==", wantStatus: http.StatusUnauthorized},
+		{name: "empty token", authorization: "Bearer  ", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", authorization: "Bearer bad", wantStatus: http.StatusUnauthorized},
+		{name: "authenticator error", authorization: "Bearer broken", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if user, ok := UserFromContext(r.Context()); ok {
+					gotUser = user.Name
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			Middleware(authenticator, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotUser != tt.wantUser {
+				t.Errorf("user = %q, want %q", gotUser, tt.wantUser)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("401 response has no WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestUnionAuthenticator(t *testing.T) {
+	static := &StaticTokenAuthenticator{tokens: map[string]*UserInfo{"tok1": {Name: "alice"}}}
+	rejecting := authenticatorFunc(func(ctx context.Context, token string) (*UserInfo, error) {
+		return nil, ErrUnauthenticated
+	})
+	broken := authenticatorFunc(func(ctx context.Context, token string) (*UserInfo, error) {
+		return nil, errors.New("token review failed")
+	})
+
+	tests := []struct {
+		name                string
+		authenticators      []Authenticator
+		token               string
+		wantUser            string
+		wantUnauthenticated bool
+	}{
+		{name: "first accepts", authenticators: []Authenticator{static, broken}, token: "tok1", wantUser: "alice"},
+		{name: "later accepts", authenticators: []Authenticator{rejecting, broken, static}, token: "tok1", wantUser: "alice"},
+		{name: "all reject", authenticators: []Authenticator{static, rejecting}, token: "tok2", wantUnauthenticated: true},
+		{name: "errors are reported", authenticators: []Authenticator{static, broken}, token: "tok2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewUnionAuthenticator(tt.authenticators...).AuthenticateToken(context.Background(), tt.token)
+			if tt.wantUser != "" {
+				if err != nil || user.Name != tt.wantUser {
+					t.Fatalf("AuthenticateToken() = %v, %v, want %s", user, err, tt.wantUser)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("AuthenticateToken() = %v, want an error", user)
+			}
+			if errors.Is(err, ErrUnauthenticated) != tt.wantUnauthenticated {
+				t.Errorf("AuthenticateToken() error = %v, want ErrUnauthenticated %t", err, tt.wantUnauthenticated)
+			}
+		})
+	}
+}
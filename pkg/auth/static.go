@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StaticTokenAuthenticator authenticates callers against a fixed set of
+// tokens loaded from a file
+type StaticTokenAuthenticator struct {
+	tokens map[string]*UserInfo
+}
+
+// NewStaticTokenAuthenticator loads tokens from a CSV file in the same
+// format as the kube-apiserver token file:
+//
+//	token,user,uid,"group1,group2"
+//
+// The uid and groups columns are optional. Blank lines and lines starting
+// with # are ignored.
+func NewStaticTokenAuthenticator(path string) (*StaticTokenAuthenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	tokens := make(map[string]*UserInfo)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token file %s: %w", path, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("token file %s line %d: expected at least token and user", path, line)
+		}
+		if _, exists := tokens[record[0]]; exists {
+			return nil, fmt.Errorf("token file %s line %d: duplicate token", path, line)
+		}
+
+		user := &UserInfo{Name: record[1]}
+		if len(record) > 2 {
+			user.UID = record[2]
+		}
+		if len(record) > 3 && record[3] != "" {
+			for _, group := range strings.Split(record[3], ",") {
+				user.Groups = append(user.Groups, strings.TrimSpace(group))
+			}
+		}
+		tokens[record[0]] = user
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %s contains no tokens", path)
+	}
+
+	return &StaticTokenAuthenticator{tokens: tokens}, nil
+}
+
+// AuthenticateToken implements Authenticator
+func (a *StaticTokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	// Compare against every token in constant time so the response time
+	// doesn't reveal how much of a token matched
+	var match *UserInfo
+	for candidate, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			match = user
+		}
+	}
+
+	if match == nil {
+		return nil, ErrUnauthenticated
+	}
+	return match, nil
+}
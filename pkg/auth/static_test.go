@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewStaticTokenAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]*UserInfo
+		wantErr string
+	}{
+		{
+			name: "all columns",
+			content: "# token,user,uid,groups\n" +
+				"\n" +
+				"tok1,alice,1,\"viewers, auditors\"\n" +
+				"tok2,bob,2\n" +
+				"tok3,carol\n",
+			want: map[string]*UserInfo{
+				"tok1": {Name: "alice", UID: "1", Groups: []string{"viewers", "auditors"}},
+				"tok2": {Name: "bob", UID: "2"},
+				"tok3": {Name: "carol"},
+			},
+		},
+		{
+			name:    "missing user",
+			content: "tok1,alice\ntok2\n",
+			wantErr: "line 2: expected at least token and user",
+		},
+		{
+			name:    "empty token",
+			content: ",alice\n",
+			wantErr: "line 1: expected at least token and user",
+		},
+		{
+			name:    "duplicate token",
+			content: "tok1,alice\ntok1,bob\n",
+			wantErr: "line 2: duplicate token",
+		},
+		{
+			name:    "no tokens",
+			content: "# nothing here\n",
+			wantErr: "contains no tokens",
+		},
+		{
+			name:    "malformed CSV",
+			content: "tok1,\"alice\n",
+			wantErr: "failed to parse token file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens.csv")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			authenticator, err := NewStaticTokenAuthenticator(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewStaticTokenAuthenticator() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewStaticTokenAuthenticator() error = %v", err)
+			}
+			if !reflect.DeepEqual(authenticator.tokens, tt.want) {
+				t.Errorf("tokens = %+v, want %+v", authenticator.tokens, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStaticTokenAuthenticatorMissingFile(t *testing.T) {
+	_, err := NewStaticTokenAuthenticator(filepath.Join(t.TempDir(), "missing.csv"))
+	if err == nil || !strings.Contains(err.Error(), "failed to open token file") {
+		t.Errorf("NewStaticTokenAuthenticator() error = %v, want a failure to open the file", err)
+	}
+}
+
+func TestStaticTokenAuthenticate(t *testing.T) {
+	authenticator := &StaticTokenAuthenticator{tokens: map[string]*UserInfo{
+		"tok1": {Name: "alice"},
+		"tok2": {Name: "bob"},
+	}}
+
+	tests := []struct {
+		token    string
+		wantUser string
+	}{
+		{token: "tok1", wantUser: "alice"},
+		{token: "tok2", wantUser: "bob"},
+		{token: "tok"},
+		{token: "tok12"},
+		{token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			user, err := authenticator.AuthenticateToken(context.Background(), tt.token)
+			if tt.wantUser == "" {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("AuthenticateToken(%q) error = %v, want ErrUnauthenticated", tt.token, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateToken(%q) error = %v", tt.token, err)
+			}
+			if user.Name != tt.wantUser {
+				t.Errorf("AuthenticateToken(%q) = %s, want %s", tt.token, user.Name, tt.wantUser)
+			}
+		})
+	}
+}
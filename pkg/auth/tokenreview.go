@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tokenReviewCacheTTL is how long a successful TokenReview is reused
+const tokenReviewCacheTTL = time.Minute
+
+// TokenReviewAuthenticator validates ServiceAccount and user tokens with
+// the Kubernetes TokenReview API
+type TokenReviewAuthenticator struct {
+	client    kubernetes.Interface
+	audiences []string
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedReview
+}
+
+type cachedReview struct {
+	user    *UserInfo
+	expires time.Time
+}
+
+// NewTokenReviewAuthenticator creates an authenticator that submits tokens
+// to the API server for review. If audiences is non-empty the token must be
+// valid for at least one of them.
+func NewTokenReviewAuthenticator(client kubernetes.Interface, audiences []string) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{
+		client:    client,
+		audiences: audiences,
+		cache:     make(map[[sha256.Size]byte]cachedReview),
+	}
+}
+
+// AuthenticateToken implements Authenticator
+func (a *TokenReviewAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	key := sha256.Sum256([]byte(token))
+
+	a.mu.Lock()
+	cached, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.user, nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: a.audiences,
+		},
+	}
+
+	result, err := a.client.AuthenticationV1().TokenReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("token review failed: %w", err)
+	}
+
+	if !result.Status.Authenticated {
+		return nil, ErrUnauthenticated
+	}
+	// An API server that ignores spec.audiences reports none; the token may
+	// then be meant for another service
+	if len(a.audiences) > 0 && !anyAudience(result.Status.Audiences, a.audiences) {
+		return nil, ErrUnauthenticated
+	}
+
+	user := &UserInfo{
+		Name:   result.Status.User.Username,
+		UID:    result.Status.User.UID,
+		Groups: result.Status.User.Groups,
+	}
+
+	a.mu.Lock()
+	a.pruneExpired()
+	a.cache[key] = cachedReview{user: user, expires: time.Now().Add(tokenReviewCacheTTL)}
+	a.mu.Unlock()
+
+	return user, nil
+}
+
+// pruneExpired drops expired cache entries. Callers must hold a.mu.
+func (a *TokenReviewAuthenticator) pruneExpired() {
+	now := time.Now()
+	for key, cached := range a.cache {
+		if now.After(cached.expires) {
+			delete(a.cache, key)
+		}
+	}
+}
+
+// anyAudience reports whether the reviewed audiences include a wanted one
+func anyAudience(reviewed, wanted []string) bool {
+	for _, audience := range reviewed {
+		for _, want := range wanted {
+			if audience == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeTokenReviews returns a clientset whose TokenReview API authenticates
+// the token "good" as alice, valid for the audience "compliance-mcp", and
+// counts the reviews it serves. Like the API server, it reports the
+// requested audiences the token is valid for.
+func fakeTokenReviews(reviews *int) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if review.Spec.Token == "good" {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "alice", UID: "1", Groups: []string{"viewers"}},
+			}
+			for _, audience := range review.Spec.Audiences {
+				if audience == "compliance-mcp" {
+					review.Status.Audiences = append(review.Status.Audiences, audience)
+				}
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestTokenReviewAuthenticate(t *testing.T) {
+	var reviews int
+	authenticator := NewTokenReviewAuthenticator(fakeTokenReviews(&reviews), nil)
+
+	want := &UserInfo{Name: "alice", UID: "1", Groups: []string{"viewers"}}
+	for i := 0; i < 2; i++ {
+		user, err := authenticator.AuthenticateToken(context.Background(), "good")
+		if err != nil {
+			t.Fatalf("AuthenticateToken() error = %v", err)
+		}
+		if !reflect.DeepEqual(user, want) {
+			t.Errorf("AuthenticateToken() = %+v, want %+v", user, want)
+		}
+	}
+	if reviews != 1 {
+		t.Errorf("got %d token reviews for a cached token, want 1", reviews)
+	}
+
+	// Rejected tokens are reviewed again on every call
+	for i := 0; i < 2; i++ {
+		if _, err := authenticator.AuthenticateToken(context.Background(), "bad"); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("AuthenticateToken() error = %v, want ErrUnauthenticated", err)
+		}
+	}
+	if reviews != 3 {
+		t.Errorf("got %d token reviews, want 3", reviews)
+	}
+}
+
+func TestTokenReviewAuthenticateAudiences(t *testing.T) {
+	tests := []struct {
+		name      string
+		audiences []string
+		// ignoreAudiences makes the API server authenticate the token
+		// without reporting audiences, as one that doesn't support them does
+		ignoreAudiences bool
+		wantErr         bool
+	}{
+		{name: "no audiences configured"},
+		{name: "token valid for the audience", audiences: []string{"compliance-mcp"}},
+		{name: "token valid for one of the audiences", audiences: []string{"other", "compliance-mcp"}},
+		{name: "token for another audience", audiences: []string{"other"}, wantErr: true},
+		{name: "audiences not reported", audiences: []string{"compliance-mcp"}, ignoreAudiences: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews int
+			client := fakeTokenReviews(&reviews)
+			if tt.ignoreAudiences {
+				client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					reviews++
+					review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+					review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "alice"}}
+					return true, review, nil
+				})
+			}
+			authenticator := NewTokenReviewAuthenticator(client, tt.audiences)
+
+			// Rejections are not cached, so each call is reviewed again
+			for i := 0; i < 2; i++ {
+				_, err := authenticator.AuthenticateToken(context.Background(), "good")
+				if tt.wantErr && !errors.Is(err, ErrUnauthenticated) {
+					t.Fatalf("AuthenticateToken() error = %v, want ErrUnauthenticated", err)
+				}
+				if !tt.wantErr && err != nil {
+					t.Fatalf("AuthenticateToken() error = %v", err)
+				}
+			}
+			if want := map[bool]int{true: 2, false: 1}[tt.wantErr]; reviews != want {
+				t.Errorf("got %d token reviews, want %d", reviews, want)
+			}
+		})
+	}
+}
+
+func TestTokenReviewAuthenticateAPIError(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	_, err := NewTokenReviewAuthenticator(client, nil).AuthenticateToken(context.Background(), "good")
+	if err == nil || errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("AuthenticateToken() error = %v, want the API error", err)
+	}
+}
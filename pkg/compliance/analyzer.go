@@ -44,16 +44,25 @@ type DiagnosisResult struct {
 	Issues      []Issue
 	Warnings    []Issue
 	Suggestions []string
+	// Partial is set when analysis stopped early because the context was
+	// cancelled or its deadline passed
+	Partial *PartialResult
 }
 
 // Analyzer analyzes compliance operator state and detects issues
 type Analyzer struct {
-	client *ComplianceClient
+	client     *ComplianceClient
+	thresholds Thresholds
 }
 
 // NewAnalyzer creates a new analyzer
 func NewAnalyzer(client *ComplianceClient) *Analyzer {
-	return &Analyzer{client: client}
+	return &Analyzer{client: client, thresholds: DefaultThresholds()}
+}
+
+// SetThresholds sets when the analyzer reports stuck scans and restarting pods
+func (a *Analyzer) SetThresholds(thresholds Thresholds) {
+	a.thresholds = thresholds
 }
 
 // AnalyzeAll performs comprehensive analysis of the compliance operator
@@ -76,8 +85,17 @@ func (a *Analyzer) AnalyzeAll(ctx context.Context) (*DiagnosisResult, error) {
 
 	// Detect failed pods
 	for _, scan := range scans {
+		if err := ctx.Err(); err != nil {
+			result.Partial = result.Partial.addSkipped(err, fmt.Sprintf("pod, resource and permission checks for scan %s", scan.Name))
+			continue
+		}
+
 		pods, err := a.client.GetScannerPods(ctx, scan.Name)
 		if err != nil {
+			a.client.logger.Log(ctx, LogLevelWarning, "Failed to get scanner pods, skipping pod checks for scan", map[string]interface{}{
+				"scan":  scan.Name,
+				"error": err.Error(),
+			})
 			continue
 		}
 
@@ -90,6 +108,11 @@ func (a *Analyzer) AnalyzeAll(ctx context.Context) (*DiagnosisResult, error) {
 		permissionIssues, err := a.DetectPermissionIssuesForScan(ctx, scan.Name)
 		if err == nil {
 			result.Issues = append(result.Issues, permissionIssues...)
+		} else {
+			a.client.logger.Log(ctx, LogLevelWarning, "Failed to check events for permission issues", map[string]interface{}{
+				"scan":  scan.Name,
+				"error": err.Error(),
+			})
 		}
 	}
 
@@ -107,7 +130,7 @@ func (a *Analyzer) AnalyzeAll(ctx context.Context) (*DiagnosisResult, error) {
 	result.Warnings = warnings
 
 	// Add general suggestions
-	if len(criticalIssues) == 0 && len(warnings) == 0 {
+	if len(criticalIssues) == 0 && len(warnings) == 0 && result.Partial == nil {
 		result.Suggestions = append(result.Suggestions, "No issues detected. Compliance operator appears to be functioning normally.")
 	}
 
@@ -124,8 +147,8 @@ func (a *Analyzer) DetectStuckScans(ctx context.Context, scans []ComplianceScan)
 			if scan.Status.StartTimestamp != nil {
 				elapsed := time.Since(scan.Status.StartTimestamp.Time)
 
-				// If running for more than 30 minutes, it's likely stuck
-				if elapsed > 30*time.Minute {
+				// If running for longer than the threshold, it's likely stuck
+				if elapsed > a.thresholds.StuckRunningAfter {
 					issue := Issue{
 						Type:        IssueTypeStuckScan,
 						Severity:    SeverityCritical,
@@ -140,7 +163,7 @@ func (a *Analyzer) DetectStuckScans(ctx context.Context, scans []ComplianceScan)
 
 		// Check for scans stuck in other phases
 		if scan.Status.Phase == PhaseLaunching {
-			if scan.Status.StartTimestamp != nil && time.Since(scan.Status.StartTimestamp.Time) > 10*time.Minute {
+			if scan.Status.StartTimestamp != nil && time.Since(scan.Status.StartTimestamp.Time) > a.thresholds.StuckLaunchingAfter {
 				issue := Issue{
 					Type:        IssueTypeStuckScan,
 					Severity:    SeverityCritical,
@@ -227,7 +250,7 @@ func (a *Analyzer) DetectFailedPods(ctx context.Context, pods []corev1.Pod) []Is
 
 		// Check for high restart count
 		for _, cs := range pod.Status.ContainerStatuses {
-			if cs.RestartCount > 5 {
+			if cs.RestartCount > a.thresholds.RestartWarning {
 				issue := Issue{
 					Type:        IssueTypeFailedPod,
 					Severity:    SeverityWarning,
@@ -351,6 +374,7 @@ func FormatDiagnosisResult(result *DiagnosisResult) string {
 	var output strings.Builder
 
 	output.WriteString("# Compliance Operator Diagnosis\n\n")
+	output.WriteString(FormatPartialResult(result.Partial))
 
 	if len(result.Issues) == 0 && len(result.Warnings) == 0 {
 		output.WriteString("✅ No critical issues detected.\n\n")
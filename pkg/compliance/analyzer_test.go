@@ -0,0 +1,45 @@
+package compliance
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDetectStuckScans(t *testing.T) {
+	started := func(phase ComplianceScanPhase, ago time.Duration) ComplianceScan {
+		object := scanObject("ocp4-cis", "cis-suite", "ocp4-cis")
+		object.Object["status"] = map[string]interface{}{
+			"phase":          string(phase),
+			"startTimestamp": time.Now().Add(-ago).UTC().Format(time.RFC3339),
+		}
+		scan, err := unstructuredToComplianceScan(object)
+		if err != nil {
+			t.Fatalf("unstructuredToComplianceScan() error = %v", err)
+		}
+		return scan
+	}
+
+	thresholds := DefaultThresholds()
+	tests := []struct {
+		name  string
+		scan  ComplianceScan
+		stuck bool
+	}{
+		{name: "running within the threshold", scan: started(PhaseRunning, thresholds.StuckRunningAfter/2)},
+		{name: "running past the threshold", scan: started(PhaseRunning, 2*thresholds.StuckRunningAfter), stuck: true},
+		{name: "launching within the threshold", scan: started(PhaseLaunching, thresholds.StuckLaunchingAfter/2)},
+		{name: "launching past the threshold", scan: started(PhaseLaunching, 2*thresholds.StuckLaunchingAfter), stuck: true},
+		{name: "no start timestamp", scan: ComplianceScan{Status: ComplianceScanStatus{Phase: PhaseRunning}}},
+	}
+
+	analyzer := NewAnalyzer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := analyzer.DetectStuckScans(context.Background(), []ComplianceScan{tt.scan})
+			if stuck := len(issues) > 0; stuck != tt.stuck {
+				t.Errorf("DetectStuckScans() = %v, want stuck %t", issues, tt.stuck)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,6 +23,9 @@ type ComplianceClient struct {
 	dynamicClient dynamic.Interface
 	kubeClient    kubernetes.Interface
 	namespace     string
+	host          string
+	logger        Logger
+	observer      APIObserver
 }
 
 // GVRs for compliance resources
@@ -50,11 +54,16 @@ var (
 
 // NewComplianceClient creates a new compliance client
 func NewComplianceClient(namespace string) (*ComplianceClient, error) {
-	config, err := getKubeConfig()
+	config, err := GetKubeConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
 
+	return NewComplianceClientForConfig(config, namespace)
+}
+
+// NewComplianceClientForConfig creates a new compliance client from a REST config
+func NewComplianceClientForConfig(config *rest.Config, namespace string) (*ComplianceClient, error) {
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
@@ -69,11 +78,48 @@ func NewComplianceClient(namespace string) (*ComplianceClient, error) {
 		dynamicClient: dynamicClient,
 		kubeClient:    kubeClient,
 		namespace:     namespace,
+		host:          config.Host,
+		logger:        nopLogger{},
 	}, nil
 }
 
-// getKubeConfig gets the Kubernetes config from various sources
-func getKubeConfig() (*rest.Config, error) {
+// SetLogger sets the logger that receives API call events
+func (c *ComplianceClient) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.logger = logger
+}
+
+// SetAPIObserver sets the observer notified of every API call
+func (c *ComplianceClient) SetAPIObserver(observer APIObserver) {
+	c.observer = observer
+}
+
+// Namespace returns the namespace the client operates in
+func (c *ComplianceClient) Namespace() string {
+	return c.namespace
+}
+
+// InNamespace returns a client that shares this client's connections and
+// logger but operates in another namespace
+func (c *ComplianceClient) InNamespace(namespace string) *ComplianceClient {
+	if namespace == c.namespace {
+		return c
+	}
+
+	clone := *c
+	clone.namespace = namespace
+	return &clone
+}
+
+// Host returns the API server address of the cluster the client talks to
+func (c *ComplianceClient) Host() string {
+	return c.host
+}
+
+// GetKubeConfig gets the Kubernetes config from various sources
+func GetKubeConfig() (*rest.Config, error) {
 	// Try KUBECONFIG env var first
 	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
 		config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
@@ -102,7 +148,9 @@ func getKubeConfig() (*rest.Config, error) {
 
 // GetComplianceSuites returns all compliance suites in the namespace
 func (c *ComplianceClient) GetComplianceSuites(ctx context.Context) ([]ComplianceSuite, error) {
+	start := time.Now()
 	list, err := c.dynamicClient.Resource(ComplianceSuiteGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	c.logAPICall(ctx, "list", "compliancesuites", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list compliance suites: %w", err)
 	}
@@ -121,7 +169,9 @@ func (c *ComplianceClient) GetComplianceSuites(ctx context.Context) ([]Complianc
 
 // GetComplianceSuite returns a specific compliance suite
 func (c *ComplianceClient) GetComplianceSuite(ctx context.Context, name string) (*ComplianceSuite, error) {
+	start := time.Now()
 	obj, err := c.dynamicClient.Resource(ComplianceSuiteGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	c.logAPICall(ctx, "get", "compliancesuites", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get compliance suite %s: %w", name, err)
 	}
@@ -141,7 +191,9 @@ func (c *ComplianceClient) GetComplianceScans(ctx context.Context, suiteLabel st
 		listOpts.LabelSelector = fmt.Sprintf("%s=%s", SuiteLabel, suiteLabel)
 	}
 
+	start := time.Now()
 	list, err := c.dynamicClient.Resource(ComplianceScanGVR).Namespace(c.namespace).List(ctx, listOpts)
+	c.logAPICall(ctx, "list", "compliancescans", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list compliance scans: %w", err)
 	}
@@ -160,7 +212,9 @@ func (c *ComplianceClient) GetComplianceScans(ctx context.Context, suiteLabel st
 
 // GetComplianceScan returns a specific compliance scan
 func (c *ComplianceClient) GetComplianceScan(ctx context.Context, name string) (*ComplianceScan, error) {
+	start := time.Now()
 	obj, err := c.dynamicClient.Resource(ComplianceScanGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	c.logAPICall(ctx, "get", "compliancescans", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get compliance scan %s: %w", name, err)
 	}
@@ -183,7 +237,9 @@ func (c *ComplianceClient) GetComplianceCheckResults(ctx context.Context, scanNa
 		listOpts.LabelSelector = fmt.Sprintf("%s,%s=%s", listOpts.LabelSelector, "compliance.openshift.io/check-status", statusFilter)
 	}
 
+	start := time.Now()
 	list, err := c.dynamicClient.Resource(ComplianceCheckResultGVR).Namespace(c.namespace).List(ctx, listOpts)
+	c.logAPICall(ctx, "list", "compliancecheckresults", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list check results: %w", err)
 	}
@@ -207,7 +263,9 @@ func (c *ComplianceClient) GetComplianceRemediations(ctx context.Context, scanNa
 		listOpts.LabelSelector = fmt.Sprintf("%s=%s", ScanLabel, scanName)
 	}
 
+	start := time.Now()
 	list, err := c.dynamicClient.Resource(ComplianceRemediationGVR).Namespace(c.namespace).List(ctx, listOpts)
+	c.logAPICall(ctx, "list", "complianceremediations", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list remediations: %w", err)
 	}
@@ -226,9 +284,11 @@ func (c *ComplianceClient) GetComplianceRemediations(ctx context.Context, scanNa
 
 // GetOperatorPods returns compliance operator pods
 func (c *ComplianceClient) GetOperatorPods(ctx context.Context) ([]corev1.Pod, error) {
+	start := time.Now()
 	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: "name=compliance-operator",
 	})
+	c.logAPICall(ctx, "list", "pods", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list operator pods: %w", err)
 	}
@@ -238,9 +298,11 @@ func (c *ComplianceClient) GetOperatorPods(ctx context.Context) ([]corev1.Pod, e
 
 // GetScannerPods returns scanner pods for a specific scan
 func (c *ComplianceClient) GetScannerPods(ctx context.Context, scanName string) ([]corev1.Pod, error) {
+	start := time.Now()
 	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s,workload=scanner", ScanLabel, scanName),
 	})
+	c.logAPICall(ctx, "list", "pods", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list scanner pods: %w", err)
 	}
@@ -254,7 +316,9 @@ func (c *ComplianceClient) GetPodLogs(ctx context.Context, podName string, tailL
 		TailLines: &tailLines,
 	})
 
+	start := time.Now()
 	logs, err := req.Stream(ctx)
+	c.logAPICall(ctx, "get", "pods/log", start, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod logs: %w", err)
 	}
@@ -268,9 +332,11 @@ func (c *ComplianceClient) GetPodLogs(ctx context.Context, podName string, tailL
 
 // GetEvents returns events for a specific object
 func (c *ComplianceClient) GetEvents(ctx context.Context, objectKind, objectName string) ([]corev1.Event, error) {
+	start := time.Now()
 	events, err := c.kubeClient.CoreV1().Events(c.namespace).List(ctx, metav1.ListOptions{
 		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", objectKind, objectName),
 	})
+	c.logAPICall(ctx, "list", "events", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list events: %w", err)
 	}
@@ -332,6 +398,7 @@ func unstructuredToComplianceScan(obj *unstructured.Unstructured) (ComplianceSca
 		scan.Spec.ScanType = ComplianceScanType(scanType)
 		scan.Spec.Profile, _, _ = unstructured.NestedString(spec, "profile")
 		scan.Spec.Content, _, _ = unstructured.NestedString(spec, "content")
+		scan.Spec.NodeSelector, _, _ = unstructured.NestedStringMap(spec, "nodeSelector")
 	}
 
 	// Extract status
@@ -343,11 +410,28 @@ func unstructuredToComplianceScan(obj *unstructured.Unstructured) (ComplianceSca
 		scan.Status.Result = ComplianceScanResult(result)
 
 		scan.Status.ErrorMessage, _, _ = unstructured.NestedString(status, "errorMessage")
+		scan.Status.StartTimestamp = nestedTime(status, "startTimestamp")
+		scan.Status.EndTimestamp = nestedTime(status, "endTimestamp")
 	}
 
 	return scan, nil
 }
 
+// nestedTime parses an RFC 3339 timestamp field, returning nil if it is
+// missing or malformed
+func nestedTime(obj map[string]interface{}, field string) *metav1.Time {
+	value, found, _ := unstructured.NestedString(obj, field)
+	if !found || value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	t := metav1.NewTime(parsed)
+	return &t
+}
+
 func unstructuredToCheckResult(obj *unstructured.Unstructured) (ComplianceCheckResult, error) {
 	result := ComplianceCheckResult{
 		TypeMeta: metav1.TypeMeta{
@@ -380,9 +464,10 @@ func unstructuredToRemediation(obj *unstructured.Unstructured) (ComplianceRemedi
 			APIVersion: obj.GetAPIVersion(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      obj.GetName(),
-			Namespace: obj.GetNamespace(),
-			Labels:    obj.GetLabels(),
+			Name:            obj.GetName(),
+			Namespace:       obj.GetNamespace(),
+			Labels:          obj.GetLabels(),
+			OwnerReferences: obj.GetOwnerReferences(),
 		},
 	}
 
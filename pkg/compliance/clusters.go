@@ -0,0 +1,145 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// DefaultClusterName names the cluster found by GetKubeConfig when no
+// cluster registry is configured
+const DefaultClusterName = "default"
+
+// clusterServiceVersionGVR is the OLM resource that records the installed
+// operator version
+var clusterServiceVersionGVR = schema.GroupVersionResource{
+	Group:    "operators.coreos.com",
+	Version:  "v1alpha1",
+	Resource: "clusterserviceversions",
+}
+
+// Cluster is a named cluster the server can query
+type Cluster struct {
+	Name   string
+	Config *rest.Config
+}
+
+// LoadKubeconfigContexts returns a cluster for each context in a kubeconfig
+// file, named after the context. An empty contexts list selects every
+// context. The current context, if selected, comes first.
+func LoadKubeconfigContexts(path string, contexts []string) ([]Cluster, error) {
+	raw, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+
+	names := contexts
+	if len(names) == 0 {
+		for name := range raw.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var clusters []Cluster
+	for _, name := range names {
+		if _, ok := raw.Contexts[name]; !ok {
+			return nil, fmt.Errorf("kubeconfig %s has no context %q", path, name)
+		}
+
+		config, err := clientcmd.NewNonInteractiveClientConfig(*raw, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build config for context %q in %s: %w", name, path, err)
+		}
+
+		cluster := Cluster{Name: name, Config: config}
+		if name == raw.CurrentContext {
+			clusters = append([]Cluster{cluster}, clusters...)
+		} else {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("kubeconfig %s has no contexts", path)
+	}
+	return clusters, nil
+}
+
+// LoadKubeconfigDirectory returns a cluster for each kubeconfig file in a
+// directory, using the file's current context and named after the file
+// without its extension. Hidden files and subdirectories are ignored.
+func LoadKubeconfigDirectory(dir string) ([]Cluster, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig directory %s: %w", dir, err)
+	}
+
+	var clusters []Cluster
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		config, err := clientcmd.BuildConfigFromFlags("", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		clusters = append(clusters, Cluster{Name: name, Config: config})
+	}
+
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("kubeconfig directory %s has no kubeconfig files", dir)
+	}
+	return clusters, nil
+}
+
+// GetOperatorVersion returns the installed Compliance Operator version from
+// its OLM ClusterServiceVersion, falling back to the operator pod's image
+// tag when the operator wasn't installed by OLM
+func (c *ComplianceClient) GetOperatorVersion(ctx context.Context) (string, error) {
+	start := time.Now()
+	list, err := c.dynamicClient.Resource(clusterServiceVersionGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	c.logAPICall(ctx, "list", clusterServiceVersionGVR.Resource, start, err)
+	if err == nil {
+		for _, csv := range list.Items {
+			if !strings.HasPrefix(csv.GetName(), "compliance-operator") {
+				continue
+			}
+			if version, found, _ := unstructured.NestedString(csv.Object, "spec", "version"); found && version != "" {
+				return version, nil
+			}
+		}
+	}
+
+	pods, podErr := c.GetOperatorPods(ctx)
+	if podErr != nil {
+		return "", podErr
+	}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			image, digest, _ := strings.Cut(container.Image, "@")
+			if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+				return image[i+1:], nil
+			}
+			if digest != "" {
+				return digest, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no compliance operator ClusterServiceVersion or pod found in %s", c.namespace)
+}
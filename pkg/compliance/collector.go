@@ -2,7 +2,9 @@ package compliance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +18,9 @@ type ComplianceData struct {
 	Remediations   map[string][]ComplianceRemediation
 	OperatorStatus OperatorHealthStatus
 	Timestamp      time.Time
+	// Partial is set when collection stopped early because the context was
+	// cancelled or its deadline passed
+	Partial *PartialResult
 }
 
 // OperatorHealthStatus represents the health of the compliance operator
@@ -40,6 +45,42 @@ type SuiteData struct {
 	Scans        []ComplianceScan
 	CheckResults map[string][]ComplianceCheckResult
 	Remediations map[string][]ComplianceRemediation
+	Partial      *PartialResult
+}
+
+// PartialResult records what was skipped when work stopped early
+type PartialResult struct {
+	Reason  string
+	Skipped []string
+}
+
+// NewPartialResult creates a PartialResult for work stopped by err
+func NewPartialResult(err error, skipped ...string) *PartialResult {
+	return &PartialResult{
+		Reason:  DescribeContextError(err),
+		Skipped: skipped,
+	}
+}
+
+// addSkipped records a skipped step, creating the PartialResult on first use
+func (p *PartialResult) addSkipped(err error, skipped string) *PartialResult {
+	if p == nil {
+		return NewPartialResult(err, skipped)
+	}
+	p.Skipped = append(p.Skipped, skipped)
+	return p
+}
+
+// DescribeContextError explains why a context stopped
+func DescribeContextError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the deadline was exceeded"
+	case errors.Is(err, context.Canceled):
+		return "the request was cancelled"
+	default:
+		return err.Error()
+	}
 }
 
 // CheckCounts holds counts of checks by status
@@ -54,12 +95,18 @@ type CheckCounts struct {
 
 // Collector collects compliance data from the cluster
 type Collector struct {
-	client *ComplianceClient
+	client     *ComplianceClient
+	thresholds Thresholds
 }
 
 // NewCollector creates a new collector
 func NewCollector(client *ComplianceClient) *Collector {
-	return &Collector{client: client}
+	return &Collector{client: client, thresholds: DefaultThresholds()}
+}
+
+// SetThresholds sets when the collector reports operator pod problems
+func (c *Collector) SetThresholds(thresholds Thresholds) {
+	c.thresholds = thresholds
 }
 
 // CollectAllData collects all compliance data from the cluster
@@ -87,23 +134,21 @@ func (c *Collector) CollectAllData(ctx context.Context) (*ComplianceData, error)
 	// Organize scans by name and collect their check results and remediations
 	for _, scan := range allScans {
 		data.Scans[scan.Name] = scan
-
-		// Get check results for this scan
-		checkResults, err := c.client.GetComplianceCheckResults(ctx, scan.Name, "")
-		if err == nil {
-			data.CheckResults[scan.Name] = checkResults
-		}
-
-		// Get remediations for this scan
-		remediations, err := c.client.GetComplianceRemediations(ctx, scan.Name)
-		if err == nil {
-			data.Remediations[scan.Name] = remediations
-		}
 	}
+	data.Partial = c.collectScanResults(ctx, allScans, data.CheckResults, data.Remediations)
 
 	// Collect operator health status
+	if err := ctx.Err(); err != nil {
+		data.Partial = data.Partial.addSkipped(err, "operator status")
+		return data, nil
+	}
+
 	operatorStatus, err := c.collectOperatorStatus(ctx)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			data.Partial = data.Partial.addSkipped(ctxErr, "operator status")
+			return data, nil
+		}
 		return nil, fmt.Errorf("failed to collect operator status: %w", err)
 	}
 	data.OperatorStatus = operatorStatus
@@ -134,19 +179,61 @@ func (c *Collector) CollectSuiteData(ctx context.Context, suiteName string) (*Su
 	data.Scans = scans
 
 	// Collect check results and remediations for each scan
+	data.Partial = c.collectScanResults(ctx, scans, data.CheckResults, data.Remediations)
+
+	return data, nil
+}
+
+// collectScanResults collects check results and remediations for each scan.
+// Once ctx is done no further API calls are made and the remaining scans are
+// recorded in the returned PartialResult.
+func (c *Collector) collectScanResults(ctx context.Context, scans []ComplianceScan, checkResults map[string][]ComplianceCheckResult, remediations map[string][]ComplianceRemediation) *PartialResult {
+	var partial *PartialResult
+
 	for _, scan := range scans {
-		checkResults, err := c.client.GetComplianceCheckResults(ctx, scan.Name, "")
+		if err := ctx.Err(); err != nil {
+			partial = partial.addSkipped(err, fmt.Sprintf("check results and remediations for scan %s", scan.Name))
+			continue
+		}
+
+		// Get check results for this scan
+		results, err := c.client.GetComplianceCheckResults(ctx, scan.Name, "")
 		if err == nil {
-			data.CheckResults[scan.Name] = checkResults
+			checkResults[scan.Name] = results
+		} else {
+			c.logCollectionError(ctx, "check results", scan.Name, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				partial = partial.addSkipped(ctxErr, fmt.Sprintf("check results for scan %s", scan.Name))
+			}
 		}
 
-		remediations, err := c.client.GetComplianceRemediations(ctx, scan.Name)
+		// Get remediations for this scan, unless the check results used up
+		// the deadline
+		if err := ctx.Err(); err != nil {
+			partial = partial.addSkipped(err, fmt.Sprintf("remediations for scan %s", scan.Name))
+			continue
+		}
+		scanRemediations, err := c.client.GetComplianceRemediations(ctx, scan.Name)
 		if err == nil {
-			data.Remediations[scan.Name] = remediations
+			remediations[scan.Name] = scanRemediations
+		} else {
+			c.logCollectionError(ctx, "remediations", scan.Name, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				partial = partial.addSkipped(ctxErr, fmt.Sprintf("remediations for scan %s", scan.Name))
+			}
 		}
 	}
 
-	return data, nil
+	return partial
+}
+
+// logCollectionError reports an error that was skipped so collection could
+// continue with partial data
+func (c *Collector) logCollectionError(ctx context.Context, what, scanName string, err error) {
+	c.client.logger.Log(ctx, LogLevelWarning, fmt.Sprintf("Failed to collect %s, continuing without them", what), map[string]interface{}{
+		"scan":  scanName,
+		"error": err.Error(),
+	})
 }
 
 // collectOperatorStatus collects the compliance operator health status
@@ -195,7 +282,7 @@ func (c *Collector) collectOperatorStatus(ctx context.Context) (OperatorHealthSt
 			status.Issues = append(status.Issues, fmt.Sprintf("Pod %s is not ready", pod.Name))
 		}
 
-		if podStatus.Restarts > 5 {
+		if podStatus.Restarts > c.thresholds.RestartWarning {
 			status.Issues = append(status.Issues, fmt.Sprintf("Pod %s has restarted %d times", pod.Name, podStatus.Restarts))
 		}
 
@@ -243,6 +330,23 @@ func CalculateCompliancePercentage(counts CheckCounts) float64 {
 	return (float64(counts.Pass) / float64(automatedChecks)) * 100
 }
 
+// FormatPartialResult formats a notice describing an incomplete result
+func FormatPartialResult(partial *PartialResult) string {
+	if partial == nil {
+		return ""
+	}
+
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("> ⚠️ **Partial result:** stopped early because %s. The following were not collected:\n", partial.Reason))
+	for _, skipped := range partial.Skipped {
+		output.WriteString(fmt.Sprintf("> - %s\n", skipped))
+	}
+	output.WriteString("\n")
+
+	return output.String()
+}
+
 // Helper function to check if pod is ready
 func isPodReady(pod *corev1.Pod) bool {
 	for _, condition := range pod.Status.Conditions {
@@ -0,0 +1,46 @@
+package compliance
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCollectScanResultsDeadline(t *testing.T) {
+	check := testCheck("ocp4-cis", "audit_log_path", CheckFail, "high")
+	client, dynamicClient := newTestClient(checkObject(check))
+
+	// The check results call uses up the deadline
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dynamicClient.PrependReactor("list", "compliancecheckresults", func(k8stesting.Action) (bool, runtime.Object, error) {
+		cancel()
+		return false, nil, nil
+	})
+	remediationCalls := 0
+	dynamicClient.PrependReactor("list", "complianceremediations", func(k8stesting.Action) (bool, runtime.Object, error) {
+		remediationCalls++
+		return false, nil, nil
+	})
+
+	scans := []ComplianceScan{{}, {}}
+	scans[0].Name = "ocp4-cis"
+	scans[1].Name = "ocp4-cis-node-worker"
+	checkResults := make(map[string][]ComplianceCheckResult)
+	remediations := make(map[string][]ComplianceRemediation)
+	partial := NewCollector(client).collectScanResults(ctx, scans, checkResults, remediations)
+
+	if len(checkResults["ocp4-cis"]) != 1 {
+		t.Errorf("check results = %v, want the results collected before the deadline", checkResults)
+	}
+	if remediationCalls != 0 {
+		t.Errorf("listed remediations %d times after the deadline", remediationCalls)
+	}
+	want := []string{"remediations for scan ocp4-cis", "check results and remediations for scan ocp4-cis-node-worker"}
+	if partial == nil || !reflect.DeepEqual(partial.Skipped, want) {
+		t.Errorf("partial = %+v, want skipped %v", partial, want)
+	}
+}
@@ -0,0 +1,360 @@
+package compliance
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Control frameworks of the Compliance Operator's content besides
+// NISTFramework
+const (
+	CISFramework    = "CIS-OCP"
+	PCIDSSFramework = "PCI-DSS"
+)
+
+// ControlFrameworks are the frameworks the embedded fallback mapping covers
+var ControlFrameworks = []string{NISTFramework, CISFramework, PCIDSSFramework}
+
+// Where a rule's controls in a framework come from
+const (
+	ControlSourceAnnotation = "annotation"
+	ControlSourceFallback   = "fallback"
+)
+
+// fallbackControlsYAML maps rule names to controls for rules without
+// control annotations
+//
+//go:embed controls.yaml
+var fallbackControlsYAML []byte
+
+// fallbackControls is the parsed fallback mapping, keyed by rule name and
+// framework
+var fallbackControls = mustParseFallbackControls(fallbackControlsYAML)
+
+func mustParseFallbackControls(data []byte) map[string]map[string][]string {
+	var mapping struct {
+		Rules map[string]map[string][]string `json:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(data, &mapping); err != nil {
+		panic(fmt.Sprintf("invalid embedded control mapping: %v", err))
+	}
+	return mapping.Rules
+}
+
+// ControlMapping maps rules to the controls of a framework. The control
+// annotations of the cluster's Rule objects win; frameworks a rule's
+// annotations do not cover come from the embedded fallback mapping.
+// Profile objects are not read: the operator annotates them only with
+// product metadata, and the controls of a profile's rules are on the Rules.
+type ControlMapping struct {
+	rules map[string]Rule
+}
+
+// NewControlMapping builds a mapping over the rules returned by GetRules.
+// rules may be nil, e.g. when the rules cannot be listed, in which case only
+// the fallback mapping is used.
+func NewControlMapping(rules map[string]Rule) *ControlMapping {
+	return &ControlMapping{rules: rules}
+}
+
+// Controls returns the controls of a framework a rule implements, and
+// whether they come from its annotations or the fallback mapping
+func (m *ControlMapping) Controls(ruleID, framework string) ([]string, string) {
+	if controls := m.rules[ruleID].Controls[framework]; len(controls) > 0 {
+		return controls, ControlSourceAnnotation
+	}
+	if controls := fallbackControls[RuleName(ruleID)][framework]; len(controls) > 0 {
+		return controls, ControlSourceFallback
+	}
+	return nil, ""
+}
+
+// Rule returns the cluster's rule with the fallback controls added for the
+// frameworks its annotations do not cover. Rules the cluster does not have
+// carry only their ID and fallback controls.
+func (m *ControlMapping) Rule(ruleID string) Rule {
+	rule, ok := m.rules[ruleID]
+	if !ok {
+		rule = Rule{ID: ruleID}
+	}
+
+	fallback := fallbackControls[RuleName(ruleID)]
+	if len(fallback) == 0 {
+		return rule
+	}
+
+	controls := make(map[string][]string, len(rule.Controls)+len(fallback))
+	for framework, ids := range fallback {
+		controls[framework] = ids
+	}
+	for framework, ids := range rule.Controls {
+		if len(ids) > 0 {
+			controls[framework] = ids
+		}
+	}
+	rule.Controls = controls
+	return rule
+}
+
+// Rules returns Rule for the rule of every check result that has an ID
+func (m *ControlMapping) Rules(results []ComplianceCheckResult) map[string]Rule {
+	rules := make(map[string]Rule)
+	for _, check := range results {
+		if check.ID == "" {
+			continue
+		}
+		if _, ok := rules[check.ID]; !ok {
+			rules[check.ID] = m.Rule(check.ID)
+		}
+	}
+	return rules
+}
+
+// Frameworks returns the frameworks at least one of the check results' rules
+// maps to, sorted
+func (m *ControlMapping) Frameworks(results []ComplianceCheckResult) []string {
+	seen := make(map[string]bool)
+	for _, rule := range m.Rules(results) {
+		for _, framework := range rule.Frameworks() {
+			seen[framework] = true
+		}
+	}
+
+	frameworks := make([]string, 0, len(seen))
+	for framework := range seen {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+	return frameworks
+}
+
+// ControlRule is a rule contributing to a control, with its status across
+// every scan that checked it
+type ControlRule struct {
+	ID       string
+	Name     string
+	Title    string
+	Severity string
+	Status   ComplianceCheckStatus
+	// Checks are the check results of the rule, one per scan
+	Checks []string
+	Source string
+}
+
+// ControlStatus rolls up the rules of one control. The control fails when
+// any of its rules fails, needs manual review when none fails and any rule
+// is manual, and passes when its remaining rules pass.
+type ControlStatus struct {
+	Control string
+	Status  ComplianceCheckStatus
+	// Pass, Fail and Manual count the control's rules by status; Other
+	// counts errored, inconsistent, informational and not applicable rules
+	Pass   int
+	Fail   int
+	Manual int
+	Other  int
+	// Rules are sorted failing first, then by severity
+	Rules []ControlRule
+}
+
+// ControlRollup is the status of every control of a framework that the
+// assessed rules map to
+type ControlRollup struct {
+	Framework string
+	// Controls are sorted by control ID, e.g. AC-2 before AC-2(1) before
+	// AC-10
+	Controls []ControlStatus
+	// Rules counts the assessed rules, Mapped the ones that map to a control
+	// of the framework and Fallback the ones mapped by the fallback mapping
+	Rules    int
+	Mapped   int
+	Fallback int
+}
+
+// NewControlRollup groups check results by rule and the rules by the
+// controls of framework they implement
+func NewControlRollup(results []ComplianceCheckResult, mapping *ControlMapping, framework string) *ControlRollup {
+	rollup := &ControlRollup{Framework: framework}
+
+	rules := make(map[string]*ControlRule)
+	var order []string
+	for _, check := range results {
+		if check.ID == "" {
+			continue
+		}
+		rule, ok := rules[check.ID]
+		if !ok {
+			title := mapping.rules[check.ID].Title
+			if title == "" {
+				title, _, _ = strings.Cut(strings.TrimSpace(check.Description), "\n")
+			}
+			rule = &ControlRule{
+				ID:       check.ID,
+				Name:     RuleName(check.ID),
+				Title:    title,
+				Severity: strings.ToLower(check.Severity),
+				Status:   check.Status,
+			}
+			rules[check.ID] = rule
+			order = append(order, check.ID)
+		}
+		if statusRank(check.Status) > statusRank(rule.Status) {
+			rule.Status = check.Status
+		}
+		rule.Checks = append(rule.Checks, check.Name)
+	}
+	rollup.Rules = len(order)
+
+	controls := make(map[string]*ControlStatus)
+	for _, id := range order {
+		ids, source := mapping.Controls(id, framework)
+		if len(ids) == 0 {
+			continue
+		}
+		rollup.Mapped++
+		if source == ControlSourceFallback {
+			rollup.Fallback++
+		}
+
+		rule := *rules[id]
+		rule.Source = source
+		for _, controlID := range ids {
+			control, ok := controls[controlID]
+			if !ok {
+				control = &ControlStatus{Control: controlID, Status: rule.Status}
+				controls[controlID] = control
+			}
+			control.add(rule)
+		}
+	}
+
+	for _, control := range controls {
+		sort.SliceStable(control.Rules, func(i, j int) bool {
+			a, b := control.Rules[i], control.Rules[j]
+			if statusRank(a.Status) != statusRank(b.Status) {
+				return statusRank(a.Status) > statusRank(b.Status)
+			}
+			if SeverityRank(a.Severity) != SeverityRank(b.Severity) {
+				return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+			}
+			return a.Name < b.Name
+		})
+		rollup.Controls = append(rollup.Controls, *control)
+	}
+	sort.Slice(rollup.Controls, func(i, j int) bool {
+		return CompareControls(rollup.Controls[i].Control, rollup.Controls[j].Control) < 0
+	})
+
+	return rollup
+}
+
+// add counts a rule towards the control
+func (c *ControlStatus) add(rule ControlRule) {
+	switch rule.Status {
+	case CheckPass:
+		c.Pass++
+	case CheckFail:
+		c.Fail++
+	case CheckManual:
+		c.Manual++
+	default:
+		c.Other++
+	}
+	if statusRank(rule.Status) > statusRank(c.Status) {
+		c.Status = rule.Status
+	}
+	c.Rules = append(c.Rules, rule)
+}
+
+// Filter returns the controls matching control, if set, and with status, if
+// set
+func (r *ControlRollup) Filter(control string, status ComplianceCheckStatus) []ControlStatus {
+	var controls []ControlStatus
+	for _, c := range r.Controls {
+		if control != "" && !MatchesControl(c.Control, control) {
+			continue
+		}
+		if status != "" && c.Status != status {
+			continue
+		}
+		controls = append(controls, c)
+	}
+	return controls
+}
+
+// statusRank orders statuses so that the status of a rule checked by
+// several scans, or of a control, is the worst of its parts
+func statusRank(status ComplianceCheckStatus) int {
+	switch status {
+	case CheckFail:
+		return 6
+	case CheckError:
+		return 5
+	case CheckInconsistent:
+		return 4
+	case CheckManual:
+		return 3
+	case CheckPass:
+		return 2
+	case CheckInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MatchesControl reports whether control is filter or one of its
+// enhancements or subsections, case-insensitively: AC-6 matches AC-6 and
+// AC-6(1), 1.2 matches 1.2 and 1.2.1, Req-10 matches Req-10.2
+func MatchesControl(control, filter string) bool {
+	control, filter = strings.ToLower(control), strings.ToLower(strings.TrimSpace(filter))
+	if control == filter {
+		return true
+	}
+	rest, ok := strings.CutPrefix(control, filter)
+	return ok && (strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, "."))
+}
+
+// CompareControls orders control IDs naturally, comparing runs of digits as
+// numbers, so AC-2 < AC-2(1) < AC-10 and 1.2.9 < 1.2.10
+func CompareControls(a, b string) int {
+	ta, tb := controlTokens(a), controlTokens(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return na - nb
+			}
+		default:
+			if c := strings.Compare(strings.ToLower(ta[i]), strings.ToLower(tb[i])); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(ta) - len(tb)
+}
+
+// controlTokens splits a control ID into runs of digits and of other
+// characters
+func controlTokens(control string) []string {
+	var tokens []string
+	start := 0
+	for i, r := range control {
+		if i > start && unicode.IsDigit(r) != unicode.IsDigit(rune(control[i-1])) {
+			tokens = append(tokens, control[start:i])
+			start = i
+		}
+	}
+	if start < len(control) {
+		tokens = append(tokens, control[start:])
+	}
+	return tokens
+}
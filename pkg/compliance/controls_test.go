@@ -0,0 +1,189 @@
+package compliance
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCompareControls(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "AC-2", b: "AC-2", want: 0},
+		{a: "AC-2", b: "AC-10", want: -1},
+		{a: "AC-2", b: "AC-2(1)", want: -1},
+		{a: "AC-2(1)", b: "AC-2(12)", want: -1},
+		{a: "AC-2(12)", b: "AC-10", want: -1},
+		{a: "ac-2", b: "AC-2", want: 0},
+		{a: "AC-2", b: "AU-2", want: -1},
+		{a: "1.2.9", b: "1.2.10", want: -1},
+		{a: "1.2", b: "1.2.1", want: -1},
+		{a: "Req-10.2", b: "Req-2.2", want: 1},
+		{a: "", b: "AC-2", want: -1},
+	}
+
+	sign := func(n int) int {
+		switch {
+		case n < 0:
+			return -1
+		case n > 0:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := sign(CompareControls(tt.a, tt.b)); got != tt.want {
+				t.Errorf("CompareControls(%q, %q) has sign %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := sign(CompareControls(tt.b, tt.a)); got != -tt.want {
+				t.Errorf("CompareControls(%q, %q) has sign %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareControlsSort(t *testing.T) {
+	controls := []string{"SC-8(1)", "AC-10", "AC-2(1)", "SC-8", "AC-2", "AC-2(10)", "AC-3"}
+	sort.Slice(controls, func(i, j int) bool { return CompareControls(controls[i], controls[j]) < 0 })
+
+	want := []string{"AC-2", "AC-2(1)", "AC-2(10)", "AC-3", "AC-10", "SC-8", "SC-8(1)"}
+	if !reflect.DeepEqual(controls, want) {
+		t.Errorf("sorted controls = %v, want %v", controls, want)
+	}
+}
+
+func TestMatchesControl(t *testing.T) {
+	tests := []struct {
+		control, filter string
+		want            bool
+	}{
+		{control: "AC-6", filter: "AC-6", want: true},
+		{control: "AC-6(1)", filter: "AC-6", want: true},
+		{control: "AC-6(1)", filter: "ac-6", want: true},
+		{control: "AC-6", filter: " AC-6 ", want: true},
+		{control: "AC-6(1)", filter: "AC-6(1)", want: true},
+		{control: "AC-6", filter: "AC-6(1)", want: false},
+		{control: "AC-61", filter: "AC-6", want: false},
+		{control: "AC-6", filter: "AC", want: false},
+		{control: "1.2.1", filter: "1.2", want: true},
+		{control: "1.2.10", filter: "1.2.1", want: false},
+		{control: "1.20", filter: "1.2", want: false},
+		{control: "Req-10.2", filter: "Req-10", want: true},
+		{control: "Req-1", filter: "Req-10", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.control+" filtered by "+tt.filter, func(t *testing.T) {
+			if got := MatchesControl(tt.control, tt.filter); got != tt.want {
+				t.Errorf("MatchesControl(%q, %q) = %t, want %t", tt.control, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewControlRollup(t *testing.T) {
+	// Rules annotated on the cluster win over the fallback mapping
+	rules := map[string]Rule{
+		rulePrefix + "api_server_anonymous_auth": {Title: "Disable anonymous auth", Controls: map[string][]string{NISTFramework: {"AC-2"}}},
+		rulePrefix + "custom_rule":               {Controls: map[string][]string{NISTFramework: {"AC-2(1)"}}},
+	}
+
+	results := []ComplianceCheckResult{
+		testCheck("ocp4-cis", "api_server_anonymous_auth", CheckPass, "medium"),
+		testCheck("ocp4-cis", "custom_rule", CheckManual, "low"),
+		testCheck("ocp4-cis", "api_server_audit_log_path", CheckFail, "high"),
+		testCheck("ocp4-cis-node-master", "custom_rule", CheckFail, "low"),
+		testCheck("ocp4-cis", "unmapped_rule", CheckFail, "high"),
+	}
+
+	tests := []struct {
+		name      string
+		framework string
+		// wantControls are the controls in order, wantStatuses their statuses
+		wantControls []string
+		wantStatuses []ComplianceCheckStatus
+		wantRules    int
+		wantMapped   int
+		wantFallback int
+	}{
+		{
+			name:         "NIST from annotations and fallback",
+			framework:    NISTFramework,
+			wantControls: []string{"AC-2", "AC-2(1)", "AU-9", "CM-6", "CM-6(1)"},
+			wantStatuses: []ComplianceCheckStatus{CheckPass, CheckFail, CheckFail, CheckFail, CheckFail},
+			wantRules:    4,
+			wantMapped:   3,
+			wantFallback: 1,
+		},
+		{
+			name:         "CIS from fallback only",
+			framework:    CISFramework,
+			wantControls: []string{"1.2.1", "1.2.22"},
+			wantStatuses: []ComplianceCheckStatus{CheckPass, CheckFail},
+			wantRules:    4,
+			wantMapped:   2,
+			wantFallback: 2,
+		},
+		{
+			name:      "unknown framework",
+			framework: "ISO-27001",
+			wantRules: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rollup := NewControlRollup(results, NewControlMapping(rules), tt.framework)
+
+			var controls []string
+			var statuses []ComplianceCheckStatus
+			for _, control := range rollup.Controls {
+				controls = append(controls, control.Control)
+				statuses = append(statuses, control.Status)
+			}
+			if !reflect.DeepEqual(controls, tt.wantControls) {
+				t.Errorf("controls = %v, want %v", controls, tt.wantControls)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+			if rollup.Rules != tt.wantRules || rollup.Mapped != tt.wantMapped || rollup.Fallback != tt.wantFallback {
+				t.Errorf("rules, mapped, fallback = %d, %d, %d, want %d, %d, %d",
+					rollup.Rules, rollup.Mapped, rollup.Fallback, tt.wantRules, tt.wantMapped, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestControlRollupRuleChecks(t *testing.T) {
+	rules := map[string]Rule{
+		rulePrefix + "custom_rule": {Controls: map[string][]string{NISTFramework: {"AC-2"}}},
+	}
+	results := []ComplianceCheckResult{
+		testCheck("ocp4-cis-node-worker", "custom_rule", CheckPass, "low"),
+		testCheck("ocp4-cis-node-master", "custom_rule", CheckInconsistent, "low"),
+	}
+
+	rollup := NewControlRollup(results, NewControlMapping(rules), NISTFramework)
+	if len(rollup.Controls) != 1 || len(rollup.Controls[0].Rules) != 1 {
+		t.Fatalf("got %+v, want one control with one rule", rollup.Controls)
+	}
+
+	control := rollup.Controls[0]
+	rule := control.Rules[0]
+	if rule.Status != CheckInconsistent || control.Status != CheckInconsistent {
+		t.Errorf("rule, control status = %s, %s, want the worst status INCONSISTENT", rule.Status, control.Status)
+	}
+	wantChecks := []string{"ocp4-cis-node-worker-custom-rule", "ocp4-cis-node-master-custom-rule"}
+	if !reflect.DeepEqual(rule.Checks, wantChecks) {
+		t.Errorf("checks = %v, want %v", rule.Checks, wantChecks)
+	}
+	if control.Other != 1 || control.Pass != 0 {
+		t.Errorf("pass, other = %d, %d, want 0, 1", control.Pass, control.Other)
+	}
+}
@@ -0,0 +1,162 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ScanExport is a scan with its check results and remediations
+type ScanExport struct {
+	Scan         ComplianceScan
+	Results      []ComplianceCheckResult
+	Remediations []ComplianceRemediation
+}
+
+// ExportData is what the result exporters render: the scans of a suite, or
+// a single scan
+type ExportData struct {
+	// Cluster names the cluster in the server's cluster registry
+	Cluster   string
+	Host      string
+	Namespace string
+	// Suite is empty when a single scan that belongs to no suite is exported
+	Suite string
+	// Scan is set when a single scan is exported
+	Scan  string
+	Scans []ScanExport
+}
+
+// CollectExport gathers the scans and check results to export: the named
+// scan if scan is set, otherwise every scan of the suite
+func (c *ComplianceClient) CollectExport(ctx context.Context, cluster, suite, scan string) (*ExportData, error) {
+	data := &ExportData{
+		Cluster:   cluster,
+		Host:      c.host,
+		Namespace: c.namespace,
+		Suite:     suite,
+		Scan:      scan,
+	}
+
+	var scans []ComplianceScan
+	switch {
+	case scan != "":
+		found, err := c.GetComplianceScan(ctx, scan)
+		if err != nil {
+			return nil, err
+		}
+		if suite != "" && found.Labels[SuiteLabel] != suite {
+			return nil, fmt.Errorf("scan %s does not belong to suite %s", scan, suite)
+		}
+		data.Suite = found.Labels[SuiteLabel]
+		scans = []ComplianceScan{*found}
+	case suite != "":
+		if _, err := c.GetComplianceSuite(ctx, suite); err != nil {
+			return nil, err
+		}
+		var err error
+		scans, err = c.GetComplianceScans(ctx, suite)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("suite_name or scan_name is required")
+	}
+
+	sort.Slice(scans, func(i, j int) bool { return scans[i].Name < scans[j].Name })
+	for _, s := range scans {
+		results, err := c.GetComplianceCheckResults(ctx, s.Name, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get check results for scan %s: %w", s.Name, err)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+		remediations, err := c.GetComplianceRemediations(ctx, s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remediations for scan %s: %w", s.Name, err)
+		}
+
+		data.Scans = append(data.Scans, ScanExport{Scan: s, Results: results, Remediations: remediations})
+	}
+
+	return data, nil
+}
+
+// Title describes what is exported, e.g. "suite cis-compliance"
+func (d *ExportData) Title() string {
+	if d.Scan != "" {
+		return "scan " + d.Scan
+	}
+	return "suite " + d.Suite
+}
+
+// URI identifies the exported suite or scan, with parts naming a document
+// about it, e.g. compliance://<cluster>/<namespace>/suites/<suite>/<parts>
+func (d *ExportData) URI(parts ...string) string {
+	if d.Scan != "" {
+		return ExportURI(d.Cluster, d.Namespace, append([]string{"scans", d.Scan}, parts...)...)
+	}
+	return ExportURI(d.Cluster, d.Namespace, append([]string{"suites", d.Suite}, parts...)...)
+}
+
+// ExportURI builds the URI identifying an exported document
+func ExportURI(cluster, namespace string, parts ...string) string {
+	uri := "compliance://" + url.PathEscape(cluster) + "/" + url.PathEscape(namespace)
+	for _, part := range parts {
+		uri += "/" + url.PathEscape(part)
+	}
+	return uri
+}
+
+// Results returns the check results of every exported scan
+func (d *ExportData) Results() []ComplianceCheckResult {
+	var results []ComplianceCheckResult
+	for _, scan := range d.Scans {
+		results = append(results, scan.Results...)
+	}
+	return results
+}
+
+// RemediationsFor returns the remediations of a check result. The operator
+// makes the check result the owner of its remediations, which share its name
+// or add a numeric suffix.
+func (s *ScanExport) RemediationsFor(check ComplianceCheckResult) []ComplianceRemediation {
+	var remediations []ComplianceRemediation
+	for _, remediation := range s.Remediations {
+		owned := remediation.Name == check.Name
+		for _, owner := range remediation.OwnerReferences {
+			if owner.Kind == "ComplianceCheckResult" && owner.Name == check.Name {
+				owned = true
+			}
+		}
+		if owned {
+			remediations = append(remediations, remediation)
+		}
+	}
+	return remediations
+}
+
+// GetScanNodes returns the names of the nodes a Node scan's nodeSelector
+// picks, sorted
+func (c *ComplianceClient) GetScanNodes(ctx context.Context, scan ComplianceScan) ([]string, error) {
+	start := time.Now()
+	list, err := c.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(scan.Spec.NodeSelector).String(),
+	})
+	c.logAPICall(ctx, "list", "nodes", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := make([]string, 0, len(list.Items))
+	for _, node := range list.Items {
+		nodes = append(nodes, node.Name)
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
@@ -0,0 +1,276 @@
+package compliance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XCCDF ID prefixes of profiles and rules in the ComplianceAsCode content
+// the operator ships
+const (
+	profilePrefix = "xccdf_org.ssgproject.content_profile_"
+	rulePrefix    = "xccdf_org.ssgproject.content_rule_"
+)
+
+// FleetMember is a named cluster and the collector that gathers its data
+type FleetMember struct {
+	Name      string
+	Collector *Collector
+}
+
+// FleetRollup is the compliance posture of several clusters
+type FleetRollup struct {
+	// Clusters are in the order the members were given
+	Clusters []ClusterRollup
+	// Profiles lists every profile scanned in any cluster, sorted
+	Profiles []string
+	// FailingRules lists rules that failed anywhere, those failing in the
+	// most clusters first
+	FailingRules []FailingRule
+	Timestamp    time.Time
+}
+
+// ClusterRollup is one cluster's contribution to a FleetRollup
+type ClusterRollup struct {
+	Name string
+	// Profiles holds check counts per profile, summed across the profile's
+	// scans (e.g. master and worker node scans)
+	Profiles       map[string]CheckCounts
+	OperatorStatus OperatorHealthStatus
+	// Err is set when the cluster's data could not be collected at all
+	Err error
+	// Partial is set when collection stopped early
+	Partial *PartialResult
+}
+
+// FailingRule is a rule that failed in at least one cluster
+type FailingRule struct {
+	ID          string
+	Severity    string
+	Description string
+	// Clusters lists the clusters where the rule failed, sorted
+	Clusters []string
+	// Profiles lists the profiles whose scans failed the rule, sorted
+	Profiles []string
+	// Results counts failing check results across the fleet, which can
+	// exceed len(Clusters) when several scans check the rule
+	Results int
+	// Failures break Results down by cluster and profile, sorted, so a
+	// profile filter can tell which clusters failed the rule in which
+	// profile
+	Failures []RuleFailure
+}
+
+// RuleFailure counts a rule's failing check results in one profile of one
+// cluster
+type RuleFailure struct {
+	Cluster string
+	Profile string
+	Results int
+}
+
+// ForProfiles returns the rule limited to the failures in profiles match
+// accepts, and false if there are none
+func (r FailingRule) ForProfiles(match func(profile string) bool) (FailingRule, bool) {
+	filtered := r
+	filtered.Clusters, filtered.Profiles, filtered.Failures, filtered.Results = nil, nil, nil, 0
+	for _, failure := range r.Failures {
+		if !match(failure.Profile) {
+			continue
+		}
+		filtered.Failures = append(filtered.Failures, failure)
+		filtered.Results += failure.Results
+		if !containsName(filtered.Clusters, failure.Cluster) {
+			filtered.Clusters = append(filtered.Clusters, failure.Cluster)
+		}
+		if !containsName(filtered.Profiles, failure.Profile) {
+			filtered.Profiles = append(filtered.Profiles, failure.Profile)
+		}
+	}
+	sort.Strings(filtered.Profiles)
+	return filtered, len(filtered.Failures) > 0
+}
+
+// ProfileName shortens an XCCDF profile ID to the name used in
+// ScanSettingBindings, e.g. "cis" or "moderate"
+func ProfileName(profile string) string {
+	return strings.TrimPrefix(profile, profilePrefix)
+}
+
+// RuleName shortens an XCCDF rule ID to the rule's name, e.g.
+// "api_server_audit_log_path"
+func RuleName(id string) string {
+	return strings.TrimPrefix(id, rulePrefix)
+}
+
+// CollectFleet collects every member's data concurrently, running at most
+// concurrency collections at once, and rolls it up. Clusters that fail are
+// reported in their ClusterRollup rather than failing the whole rollup.
+func CollectFleet(ctx context.Context, members []FleetMember, concurrency int) *FleetRollup {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	rollup := &FleetRollup{
+		Clusters:  make([]ClusterRollup, len(members)),
+		Timestamp: time.Now(),
+	}
+	data := make([]*ComplianceData, len(members))
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, member := range members {
+		wg.Add(1)
+		go func(i int, member FleetMember) {
+			defer wg.Done()
+
+			cluster := &rollup.Clusters[i]
+			cluster.Name = member.Name
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				cluster.Err = ctx.Err()
+				return
+			}
+
+			data[i], cluster.Err = member.Collector.CollectAllData(ctx)
+		}(i, member)
+	}
+	wg.Wait()
+
+	profiles := make(map[string]bool)
+	rules := make(map[string]*FailingRule)
+	for i := range rollup.Clusters {
+		cluster := &rollup.Clusters[i]
+		if data[i] == nil {
+			continue
+		}
+		cluster.OperatorStatus = data[i].OperatorStatus
+		cluster.Partial = data[i].Partial
+		cluster.Profiles = make(map[string]CheckCounts)
+
+		for scanName, results := range data[i].CheckResults {
+			profile := scanName
+			if scan, ok := data[i].Scans[scanName]; ok && scan.Spec.Profile != "" {
+				profile = ProfileName(scan.Spec.Profile)
+			}
+			profiles[profile] = true
+			cluster.Profiles[profile] = addCheckCounts(cluster.Profiles[profile], GetCheckCounts(results))
+
+			for _, result := range results {
+				if result.Status != CheckFail {
+					continue
+				}
+				id := result.ID
+				if id == "" {
+					id = result.Name
+				}
+
+				rule, ok := rules[id]
+				if !ok {
+					rule = &FailingRule{
+						ID:          id,
+						Severity:    result.Severity,
+						Description: result.Description,
+					}
+					rules[id] = rule
+				}
+				rule.Results++
+				rule.addFailure(cluster.Name, profile)
+				if !containsName(rule.Clusters, cluster.Name) {
+					rule.Clusters = append(rule.Clusters, cluster.Name)
+				}
+				if !containsName(rule.Profiles, profile) {
+					rule.Profiles = append(rule.Profiles, profile)
+				}
+			}
+		}
+	}
+
+	for profile := range profiles {
+		rollup.Profiles = append(rollup.Profiles, profile)
+	}
+	sort.Strings(rollup.Profiles)
+
+	for _, rule := range rules {
+		sort.Strings(rule.Clusters)
+		sort.Strings(rule.Profiles)
+		sort.Slice(rule.Failures, func(i, j int) bool {
+			a, b := rule.Failures[i], rule.Failures[j]
+			if a.Cluster != b.Cluster {
+				return a.Cluster < b.Cluster
+			}
+			return a.Profile < b.Profile
+		})
+		rollup.FailingRules = append(rollup.FailingRules, *rule)
+	}
+	SortFailingRules(rollup.FailingRules)
+
+	return rollup
+}
+
+// SortFailingRules orders rules failing in the most clusters first, then by
+// severity and ID
+func SortFailingRules(rules []FailingRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if len(a.Clusters) != len(b.Clusters) {
+			return len(a.Clusters) > len(b.Clusters)
+		}
+		if SeverityRank(a.Severity) != SeverityRank(b.Severity) {
+			return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// addFailure counts a failing check result of the rule in a cluster's profile
+func (r *FailingRule) addFailure(cluster, profile string) {
+	for i := range r.Failures {
+		if r.Failures[i].Cluster == cluster && r.Failures[i].Profile == profile {
+			r.Failures[i].Results++
+			return
+		}
+	}
+	r.Failures = append(r.Failures, RuleFailure{Cluster: cluster, Profile: profile, Results: 1})
+}
+
+// SeverityRank orders check severities from unknown (0) to high (3)
+func SeverityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// addCheckCounts sums two sets of check counts
+func addCheckCounts(a, b CheckCounts) CheckCounts {
+	return CheckCounts{
+		Pass:   a.Pass + b.Pass,
+		Fail:   a.Fail + b.Fail,
+		Manual: a.Manual + b.Manual,
+		Error:  a.Error + b.Error,
+		Info:   a.Info + b.Info,
+		Total:  a.Total + b.Total,
+	}
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
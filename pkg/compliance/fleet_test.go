@@ -0,0 +1,174 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCollectFleet(t *testing.T) {
+	prod, _ := newTestClient(
+		scanObject("ocp4-cis", "cis-compliance", profilePrefix+"cis"),
+		scanObject("ocp4-cis-node-worker", "cis-compliance", profilePrefix+"cis-node"),
+		checkObject(testCheck("ocp4-cis", "audit_log_path", CheckFail, "high")),
+		checkObject(testCheck("ocp4-cis", "scc_limit_root", CheckPass, "medium")),
+		checkObject(testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckFail, "high")),
+		operatorPod("compliance-operator-0"),
+	)
+	staging, _ := newTestClient(
+		scanObject("ocp4-cis", "cis-compliance", profilePrefix+"cis"),
+		checkObject(testCheck("ocp4-cis", "audit_log_path", CheckFail, "high")),
+		checkObject(testCheck("ocp4-cis", "etcd_unique_ca", CheckFail, "medium")),
+		checkObject(testCheck("ocp4-cis", "kubelet_anonymous_auth", CheckFail, "high")),
+		operatorPod("compliance-operator-0"),
+	)
+	broken, brokenAPI := newTestClient()
+	brokenAPI.PrependReactor("list", "compliancesuites", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	rollup := CollectFleet(context.Background(), []FleetMember{
+		{Name: "prod", Collector: NewCollector(prod)},
+		{Name: "broken", Collector: NewCollector(broken)},
+		{Name: "staging", Collector: NewCollector(staging)},
+	}, 2)
+
+	var names []string
+	for _, cluster := range rollup.Clusters {
+		names = append(names, cluster.Name)
+	}
+	if want := []string{"prod", "broken", "staging"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("clusters = %v, want %v in the order given", names, want)
+	}
+
+	if rollup.Clusters[1].Err == nil {
+		t.Errorf("broken cluster has no error")
+	}
+	if rollup.Clusters[0].Err != nil || rollup.Clusters[2].Err != nil {
+		t.Errorf("cluster errors = %v, %v, want none", rollup.Clusters[0].Err, rollup.Clusters[2].Err)
+	}
+	if !rollup.Clusters[0].OperatorStatus.IsHealthy {
+		t.Errorf("prod operator is not healthy: %v", rollup.Clusters[0].OperatorStatus.Issues)
+	}
+
+	wantProfiles := map[string]CheckCounts{
+		"cis":      {Pass: 1, Fail: 1, Total: 2},
+		"cis-node": {Fail: 1, Total: 1},
+	}
+	if !reflect.DeepEqual(rollup.Clusters[0].Profiles, wantProfiles) {
+		t.Errorf("prod profiles = %+v, want %+v", rollup.Clusters[0].Profiles, wantProfiles)
+	}
+
+	if want := []string{"cis", "cis-node"}; !reflect.DeepEqual(rollup.Profiles, want) {
+		t.Errorf("profiles = %v, want %v", rollup.Profiles, want)
+	}
+
+	// Rules failing in the most clusters come first, then by severity
+	wantRules := []FailingRule{
+		{
+			ID: rulePrefix + "audit_log_path", Severity: "high", Description: "Check audit_log_path",
+			Clusters: []string{"prod", "staging"}, Profiles: []string{"cis"}, Results: 2,
+			Failures: []RuleFailure{{Cluster: "prod", Profile: "cis", Results: 1}, {Cluster: "staging", Profile: "cis", Results: 1}},
+		},
+		{
+			ID: rulePrefix + "kubelet_anonymous_auth", Severity: "high", Description: "Check kubelet_anonymous_auth",
+			Clusters: []string{"prod", "staging"}, Profiles: []string{"cis", "cis-node"}, Results: 2,
+			Failures: []RuleFailure{{Cluster: "prod", Profile: "cis-node", Results: 1}, {Cluster: "staging", Profile: "cis", Results: 1}},
+		},
+		{
+			ID: rulePrefix + "etcd_unique_ca", Severity: "medium", Description: "Check etcd_unique_ca",
+			Clusters: []string{"staging"}, Profiles: []string{"cis"}, Results: 1,
+			Failures: []RuleFailure{{Cluster: "staging", Profile: "cis", Results: 1}},
+		},
+	}
+	if !reflect.DeepEqual(rollup.FailingRules, wantRules) {
+		t.Errorf("failing rules =\n%+v\nwant\n%+v", rollup.FailingRules, wantRules)
+	}
+}
+
+func TestFailingRuleForProfiles(t *testing.T) {
+	rule := FailingRule{
+		ID:       rulePrefix + "kubelet_anonymous_auth",
+		Clusters: []string{"dev", "prod", "staging"},
+		Profiles: []string{"cis", "cis-node", "moderate-node"},
+		Results:  4,
+		Failures: []RuleFailure{
+			{Cluster: "dev", Profile: "moderate-node", Results: 1},
+			{Cluster: "prod", Profile: "cis", Results: 1},
+			{Cluster: "prod", Profile: "cis-node", Results: 1},
+			{Cluster: "staging", Profile: "cis", Results: 1},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter string
+		want   FailingRule
+		wantOK bool
+	}{
+		{
+			name:   "only clusters failing a matching profile",
+			filter: "node",
+			want: FailingRule{
+				ID:       rule.ID,
+				Clusters: []string{"dev", "prod"},
+				Profiles: []string{"cis-node", "moderate-node"},
+				Results:  2,
+				Failures: []RuleFailure{rule.Failures[0], rule.Failures[2]},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "every failure matches",
+			filter: "",
+			want:   rule,
+			wantOK: true,
+		},
+		{
+			name:   "no failure matches",
+			filter: "pci",
+			want:   FailingRule{ID: rule.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rule.ForProfiles(func(profile string) bool { return strings.Contains(profile, tt.filter) })
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForProfiles(%q) = %+v, %t, want %+v, %t", tt.filter, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProfileName(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{profile: profilePrefix + "cis", want: "cis"},
+		{profile: profilePrefix + "moderate-node", want: "moderate-node"},
+		{profile: "upstream-ocp4-cis", want: "upstream-ocp4-cis"},
+	}
+
+	for _, tt := range tests {
+		if got := ProfileName(tt.profile); got != tt.want {
+			t.Errorf("ProfileName(%q) = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityRank(t *testing.T) {
+	severities := []string{"", "unknown", "low", "Medium", "HIGH"}
+	want := []int{0, 0, 1, 2, 3}
+	for i, severity := range severities {
+		if got := SeverityRank(severity); got != want[i] {
+			t.Errorf("SeverityRank(%q) = %d, want %d", severity, got, want[i])
+		}
+	}
+}
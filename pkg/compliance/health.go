@@ -0,0 +1,60 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requiredResources are the Compliance Operator resources the tools read
+var requiredResources = []string{
+	ComplianceSuiteGVR.Resource,
+	ComplianceScanGVR.Resource,
+	ComplianceCheckResultGVR.Resource,
+	ComplianceRemediationGVR.Resource,
+}
+
+// CheckAPIServer verifies that the Kubernetes API server is reachable with
+// the client's credentials
+func (c *ComplianceClient) CheckAPIServer(ctx context.Context) error {
+	if err := c.kubeClient.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+		return fmt.Errorf("API server %s is not reachable: %w", c.host, err)
+	}
+	return nil
+}
+
+// CheckComplianceAPI verifies that the compliance.openshift.io API group is
+// served and includes the resources the tools read, i.e. that the
+// Compliance Operator CRDs are installed
+func (c *ComplianceClient) CheckComplianceAPI(ctx context.Context) error {
+	groupVersion := ComplianceSuiteGVR.GroupVersion().String()
+
+	data, err := c.kubeClient.Discovery().RESTClient().Get().AbsPath("/apis", groupVersion).Do(ctx).Raw()
+	if err != nil {
+		return fmt.Errorf("API group %s is not available (is the Compliance Operator installed?): %w", groupVersion, err)
+	}
+
+	var resources metav1.APIResourceList
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return fmt.Errorf("failed to parse discovery for %s: %w", groupVersion, err)
+	}
+
+	served := make(map[string]bool, len(resources.APIResources))
+	for _, resource := range resources.APIResources {
+		served[resource.Name] = true
+	}
+
+	var missing []string
+	for _, resource := range requiredResources {
+		if !served[resource] {
+			missing = append(missing, resource)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("API group %s does not serve %v", groupVersion, missing)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package compliance
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testNamespace is where the fake clusters run the Compliance Operator
+const testNamespace = "openshift-compliance"
+
+// testCheck builds a check result of a scan for a rule name, e.g.
+// api_server_audit_log_path
+func testCheck(scan, rule string, status ComplianceCheckStatus, severity string) ComplianceCheckResult {
+	return ComplianceCheckResult{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   scan + "-" + strings.ReplaceAll(rule, "_", "-"),
+			Labels: map[string]string{ScanLabel: scan},
+		},
+		ID:           rulePrefix + rule,
+		Status:       status,
+		Severity:     severity,
+		Description:  "Check " + rule,
+		Instructions: "Run the check for " + rule,
+	}
+}
+
+// testExport builds the export of a suite from its scans' check results
+func testExport(scans ...ScanExport) *ExportData {
+	return &ExportData{
+		Cluster:   "prod",
+		Host:      "https://api.prod.example.com:6443",
+		Namespace: testNamespace,
+		Suite:     "cis-compliance",
+		Scans:     scans,
+	}
+}
+
+// testScanExport builds a done scan with its check results
+func testScanExport(name string, scanType ComplianceScanType, results ...ComplianceCheckResult) ScanExport {
+	end := metav1.NewTime(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC))
+	return ScanExport{
+		Scan: ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{SuiteLabel: "cis-compliance"}},
+			Spec:       ComplianceScanSpec{ScanType: scanType, Profile: "ocp4-cis"},
+			Status:     ComplianceScanStatus{Phase: PhaseDone, EndTimestamp: &end},
+		},
+		Results: results,
+	}
+}
+
+// newTestClient returns a client for a fake cluster holding objects.
+// Compliance Operator resources are unstructured; pods are typed.
+func newTestClient(objects ...runtime.Object) (*ComplianceClient, *dynamicfake.FakeDynamicClient) {
+	var custom, typed []runtime.Object
+	for _, object := range objects {
+		if _, ok := object.(*unstructured.Unstructured); ok {
+			custom = append(custom, object)
+		} else {
+			typed = append(typed, object)
+		}
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			ComplianceSuiteGVR:       "ComplianceSuiteList",
+			ComplianceScanGVR:        "ComplianceScanList",
+			ComplianceCheckResultGVR: "ComplianceCheckResultList",
+			ComplianceRemediationGVR: "ComplianceRemediationList",
+		}, custom...)
+
+	client := &ComplianceClient{
+		dynamicClient: dynamicClient,
+		kubeClient:    fake.NewSimpleClientset(typed...),
+		namespace:     testNamespace,
+		host:          "https://api.example.com:6443",
+		logger:        nopLogger{},
+	}
+	return client, dynamicClient
+}
+
+// scanObject returns a done ComplianceScan of a profile in a suite
+func scanObject(name, suite, profile string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "compliance.openshift.io/v1alpha1",
+		"kind":       "ComplianceScan",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": testNamespace,
+			"labels":    map[string]interface{}{SuiteLabel: suite},
+		},
+		"spec":   map[string]interface{}{"profile": profile},
+		"status": map[string]interface{}{"phase": string(PhaseDone)},
+	}}
+}
+
+// checkObject returns the ComplianceCheckResult object for a check
+func checkObject(check ComplianceCheckResult) *unstructured.Unstructured {
+	labels := make(map[string]interface{})
+	for key, value := range check.Labels {
+		labels[key] = value
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "compliance.openshift.io/v1alpha1",
+		"kind":       "ComplianceCheckResult",
+		"metadata": map[string]interface{}{
+			"name":      check.Name,
+			"namespace": testNamespace,
+			"labels":    labels,
+		},
+		"id":           check.ID,
+		"status":       string(check.Status),
+		"severity":     check.Severity,
+		"description":  check.Description,
+		"instructions": check.Instructions,
+	}}
+}
+
+// operatorPod returns a running, ready Compliance Operator pod
+func operatorPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    map[string]string{"name": "compliance-operator"},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
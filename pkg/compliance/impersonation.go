@@ -0,0 +1,106 @@
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// impersonatedClientIdleTTL is how long an unused impersonating client is kept
+const impersonatedClientIdleTTL = 30 * time.Minute
+
+// ImpersonatingClients builds ComplianceClients that impersonate individual
+// callers, so API errors reflect the caller's own RBAC rather than the
+// server's. Clients are cached per identity.
+type ImpersonatingClients struct {
+	config    *rest.Config
+	namespace string
+	logger    Logger
+	observer  APIObserver
+
+	mu      sync.Mutex
+	clients map[string]*impersonatedClient
+}
+
+type impersonatedClient struct {
+	client   *ComplianceClient
+	lastUsed time.Time
+}
+
+// NewImpersonatingClients creates a client cache that derives impersonating
+// clients from config
+func NewImpersonatingClients(config *rest.Config, namespace string) *ImpersonatingClients {
+	return &ImpersonatingClients{
+		config:    config,
+		namespace: namespace,
+		logger:    nopLogger{},
+		clients:   make(map[string]*impersonatedClient),
+	}
+}
+
+// SetLogger sets the logger given to the clients this cache creates
+func (c *ImpersonatingClients) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.logger = logger
+}
+
+// SetAPIObserver sets the API call observer given to the clients this cache
+// creates
+func (c *ImpersonatingClients) SetAPIObserver(observer APIObserver) {
+	c.observer = observer
+}
+
+// ForUser returns a client that impersonates the given user and groups
+func (c *ImpersonatingClients) ForUser(userName, uid string, groups []string) (*ComplianceClient, error) {
+	if userName == "" {
+		return nil, fmt.Errorf("cannot impersonate a user without a name")
+	}
+
+	sortedGroups := append([]string(nil), groups...)
+	sort.Strings(sortedGroups)
+	key := strings.Join(append([]string{userName, uid}, sortedGroups...), "\x00")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.pruneIdle(now)
+
+	if cached, ok := c.clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	config := rest.CopyConfig(c.config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: userName,
+		UID:      uid,
+		Groups:   sortedGroups,
+	}
+
+	client, err := NewComplianceClientForConfig(config, c.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client impersonating %s: %w", userName, err)
+	}
+	client.SetLogger(c.logger)
+	client.SetAPIObserver(c.observer)
+
+	c.clients[key] = &impersonatedClient{client: client, lastUsed: now}
+	return client, nil
+}
+
+// pruneIdle drops clients that haven't been used recently. Callers must
+// hold c.mu.
+func (c *ImpersonatingClients) pruneIdle(now time.Time) {
+	for key, cached := range c.clients {
+		if now.Sub(cached.lastUsed) > impersonatedClientIdleTTL {
+			delete(c.clients, key)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package compliance
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JUnitMediaType is the MIME type of JUnit XML reports
+const JUnitMediaType = "application/xml"
+
+// JUnitTestSuites is a JUnit XML report with a testsuite per scan
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite holds the checks of one scan
+type JUnitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Time       string          `xml:"time,attr,omitempty"`
+	Hostname   string          `xml:"hostname,attr,omitempty"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	TestCases  []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitProperty is a name/value pair describing a testsuite
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitTestCase is one check. At most one of Failure, Error and Skipped is
+// set; none means the check passed.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *JUnitProblem `xml:"failure,omitempty"`
+	Error     *JUnitProblem `xml:"error,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitProblem describes a failed or errored check. Type carries the
+// check's severity.
+type JUnitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitSkipped describes a check that was not evaluated automatically
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnitReport builds a JUnit report with a testsuite per scan and a
+// testcase per check: FAIL is a failure, ERROR and INCONSISTENT are errors,
+// and MANUAL, INFO and NOT-APPLICABLE are skipped
+func NewJUnitReport(data *ExportData) *JUnitTestSuites {
+	report := &JUnitTestSuites{Name: "Compliance Operator " + data.Title()}
+
+	for _, export := range data.Scans {
+		scan := export.Scan
+		suite := JUnitTestSuite{
+			Name:     scan.Name,
+			Hostname: hostName(data.Host),
+			Properties: []JUnitProperty{
+				{Name: "cluster", Value: data.Cluster},
+				{Name: "namespace", Value: data.Namespace},
+				{Name: "profile", Value: ProfileName(scan.Spec.Profile)},
+				{Name: "scanType", Value: string(scan.Spec.ScanType)},
+				{Name: "result", Value: string(scan.Status.Result)},
+			},
+		}
+		if data.Suite != "" {
+			suite.Properties = append(suite.Properties, JUnitProperty{Name: "suite", Value: data.Suite})
+		}
+		if scan.Status.StartTimestamp != nil {
+			suite.Timestamp = scan.Status.StartTimestamp.UTC().Format("2006-01-02T15:04:05")
+			if scan.Status.EndTimestamp != nil {
+				suite.Time = fmt.Sprintf("%.0f", scan.Status.EndTimestamp.Sub(scan.Status.StartTimestamp.Time).Round(time.Second).Seconds())
+			}
+		}
+
+		for _, check := range export.Results {
+			testCase := JUnitTestCase{Name: check.Name, Classname: scan.Name}
+			severity := check.Severity
+			if severity == "" {
+				severity = "unknown"
+			}
+			title, _, _ := strings.Cut(strings.TrimSpace(check.Description), "\n")
+
+			switch check.Status {
+			case CheckPass:
+			case CheckFail:
+				testCase.Failure = &JUnitProblem{Message: title, Type: severity, Text: check.Instructions}
+				suite.Failures++
+			case CheckError:
+				testCase.Error = &JUnitProblem{Message: "the check could not be evaluated: " + title, Type: severity}
+				suite.Errors++
+			case CheckInconsistent:
+				testCase.Error = &JUnitProblem{Message: "the check returned different results on different nodes: " + title, Type: severity}
+				suite.Errors++
+			default:
+				testCase.Skipped = &JUnitSkipped{Message: string(check.Status)}
+				suite.Skipped++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.Tests++
+		}
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return report
+}
+
+// Marshal renders the report as an indented XML document
+func (r *JUnitTestSuites) Marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render JUnit: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// CountFailures counts the FAIL check results of a severity across every
+// exported scan, and the ERROR and INCONSISTENT ones when countErrors is set
+func CountFailures(data *ExportData, severity string, countErrors bool) int {
+	count := 0
+	for _, check := range data.Results() {
+		if !strings.EqualFold(check.Severity, severity) {
+			continue
+		}
+		switch check.Status {
+		case CheckFail:
+			count++
+		case CheckError, CheckInconsistent:
+			if countErrors {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,167 @@
+package compliance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewJUnitReport(t *testing.T) {
+	// counts are a testsuite's tests, failures, errors and skipped
+	type counts struct {
+		Tests, Failures, Errors, Skipped int
+	}
+
+	tests := []struct {
+		name       string
+		data       *ExportData
+		wantTotal  counts
+		wantSuites map[string]counts
+		// wantCases maps each testcase to failure, error, skipped or pass
+		wantCases map[string]string
+	}{
+		{
+			name: "every status",
+			data: testExport(
+				testScanExport("ocp4-cis", ScanTypePlatform,
+					testCheck("ocp4-cis", "audit_log_path", CheckFail, "high"),
+					testCheck("ocp4-cis", "etcd_unique_ca", CheckError, "medium"),
+					testCheck("ocp4-cis", "scc_limit_root", CheckPass, "low"),
+					testCheck("ocp4-cis", "idp_is_configured", CheckManual, "medium"),
+					testCheck("ocp4-cis", "routes_protected", CheckInfo, "low"),
+					testCheck("ocp4-cis", "ingress_tls", CheckNotApplicable, "low"),
+				),
+				testScanExport("ocp4-cis-node-worker", ScanTypeNode,
+					testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckInconsistent, "high"),
+				),
+			),
+			wantTotal: counts{Tests: 7, Failures: 1, Errors: 2, Skipped: 3},
+			wantSuites: map[string]counts{
+				"ocp4-cis":             {Tests: 6, Failures: 1, Errors: 1, Skipped: 3},
+				"ocp4-cis-node-worker": {Tests: 1, Errors: 1},
+			},
+			wantCases: map[string]string{
+				"ocp4-cis-audit-log-path":                     "failure",
+				"ocp4-cis-etcd-unique-ca":                     "error",
+				"ocp4-cis-scc-limit-root":                     "pass",
+				"ocp4-cis-idp-is-configured":                  "skipped",
+				"ocp4-cis-routes-protected":                   "skipped",
+				"ocp4-cis-ingress-tls":                        "skipped",
+				"ocp4-cis-node-worker-kubelet-anonymous-auth": "error",
+			},
+		},
+		{
+			name:       "no scans",
+			data:       testExport(),
+			wantSuites: map[string]counts{},
+			wantCases:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewJUnitReport(tt.data)
+
+			total := counts{Tests: report.Tests, Failures: report.Failures, Errors: report.Errors, Skipped: report.Skipped}
+			if total != tt.wantTotal {
+				t.Errorf("report counts = %+v, want %+v", total, tt.wantTotal)
+			}
+
+			suites := make(map[string]counts)
+			cases := make(map[string]string)
+			for _, suite := range report.Suites {
+				suites[suite.Name] = counts{Tests: suite.Tests, Failures: suite.Failures, Errors: suite.Errors, Skipped: suite.Skipped}
+				for _, testCase := range suite.TestCases {
+					switch {
+					case testCase.Failure != nil:
+						cases[testCase.Name] = "failure"
+					case testCase.Error != nil:
+						cases[testCase.Name] = "error"
+					case testCase.Skipped != nil:
+						cases[testCase.Name] = "skipped"
+					default:
+						cases[testCase.Name] = "pass"
+					}
+				}
+			}
+			if !reflect.DeepEqual(suites, tt.wantSuites) {
+				t.Errorf("testsuites = %+v, want %+v", suites, tt.wantSuites)
+			}
+			if !reflect.DeepEqual(cases, tt.wantCases) {
+				t.Errorf("testcases = %v, want %v", cases, tt.wantCases)
+			}
+
+			out, err := report.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !strings.HasPrefix(string(out), `<?xml version="1.0" encoding="UTF-8"?>`) {
+				t.Errorf("Marshal() does not start with an XML declaration: %.60s", out)
+			}
+		})
+	}
+}
+
+func TestJUnitFailureDetails(t *testing.T) {
+	check := testCheck("ocp4-cis", "audit_log_path", CheckFail, "")
+	check.Description = "Configure the audit log path\nThe API server should log to a file."
+	data := testExport(testScanExport("ocp4-cis", ScanTypePlatform, check))
+
+	suite := NewJUnitReport(data).Suites[0]
+	want := &JUnitProblem{Message: "Configure the audit log path", Type: "unknown", Text: "Run the check for audit_log_path"}
+	if got := suite.TestCases[0].Failure; !reflect.DeepEqual(got, want) {
+		t.Errorf("failure = %+v, want %+v", got, want)
+	}
+}
+
+func TestJUnitSuiteTime(t *testing.T) {
+	export := testScanExport("ocp4-cis", ScanTypePlatform, testCheck("ocp4-cis", "audit_log_path", CheckPass, "medium"))
+	start := metav1.NewTime(export.Scan.Status.EndTimestamp.Add(-90*time.Second - 400*time.Millisecond))
+	export.Scan.Status.StartTimestamp = &start
+
+	suite := NewJUnitReport(testExport(export)).Suites[0]
+	if suite.Timestamp != "2026-10-01T09:58:29" || suite.Time != "90" {
+		t.Errorf("timestamp, time = %q, %q, want %q, %q", suite.Timestamp, suite.Time, "2026-10-01T09:58:29", "90")
+	}
+}
+
+func TestCountFailures(t *testing.T) {
+	data := testExport(
+		testScanExport("ocp4-cis", ScanTypePlatform,
+			testCheck("ocp4-cis", "audit_log_path", CheckFail, "high"),
+			testCheck("ocp4-cis", "etcd_unique_ca", CheckFail, "HIGH"),
+			testCheck("ocp4-cis", "scc_limit_root", CheckFail, "medium"),
+			testCheck("ocp4-cis", "api_server_encryption", CheckError, "high"),
+			testCheck("ocp4-cis", "idp_is_configured", CheckManual, "high"),
+			testCheck("ocp4-cis", "ingress_tls", CheckPass, "high"),
+		),
+		testScanExport("ocp4-cis-node-worker", ScanTypeNode,
+			testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckInconsistent, "high"),
+			testCheck("ocp4-cis-node-worker", "kubelet_eviction_thresholds", CheckError, "medium"),
+		),
+	)
+
+	tests := []struct {
+		name        string
+		severity    string
+		countErrors bool
+		want        int
+	}{
+		{name: "high failures", severity: "high", want: 2},
+		{name: "high failures and errors", severity: "high", countErrors: true, want: 4},
+		{name: "medium failures", severity: "medium", want: 1},
+		{name: "medium failures and errors", severity: "medium", countErrors: true, want: 2},
+		{name: "no low checks", severity: "low", countErrors: true, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountFailures(data, tt.severity, tt.countErrors); got != tt.want {
+				t.Errorf("CountFailures(%q, %t) = %d, want %d", tt.severity, tt.countErrors, got, tt.want)
+			}
+		})
+	}
+}
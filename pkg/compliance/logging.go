@@ -0,0 +1,57 @@
+package compliance
+
+import (
+	"context"
+	"time"
+)
+
+// LogLevel represents the severity of a log event
+type LogLevel string
+
+const (
+	LogLevelDebug   LogLevel = "debug"
+	LogLevelInfo    LogLevel = "info"
+	LogLevelWarning LogLevel = "warning"
+	LogLevelError   LogLevel = "error"
+)
+
+// Logger receives structured log events from the client, collector and
+// analyzer. The context is the one passed to the operation that produced the
+// event, so implementations can route events back to the caller.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, message string, fields map[string]interface{})
+}
+
+// APIObserver receives the outcome of every Kubernetes API call made by the
+// client, for example to export metrics
+type APIObserver interface {
+	ObserveAPICall(verb, resource string, duration time.Duration, err error)
+}
+
+// nopLogger discards all log events
+type nopLogger struct{}
+
+func (nopLogger) Log(context.Context, LogLevel, string, map[string]interface{}) {}
+
+// logAPICall reports a Kubernetes API call made by the client
+func (c *ComplianceClient) logAPICall(ctx context.Context, verb, resource string, start time.Time, err error) {
+	duration := time.Since(start)
+	if c.observer != nil {
+		c.observer.ObserveAPICall(verb, resource, duration, err)
+	}
+
+	fields := map[string]interface{}{
+		"verb":       verb,
+		"resource":   resource,
+		"namespace":  c.namespace,
+		"durationMs": duration.Milliseconds(),
+	}
+
+	if err != nil {
+		fields["error"] = err.Error()
+		c.logger.Log(ctx, LogLevelWarning, "Kubernetes API call failed", fields)
+		return
+	}
+
+	c.logger.Log(ctx, LogLevelDebug, "Kubernetes API call", fields)
+}
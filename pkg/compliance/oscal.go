@@ -0,0 +1,408 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OSCALVersion is the OSCAL version of exported documents
+const OSCALVersion = "1.1.2"
+
+// OSCALMediaType is the MIME type of OSCAL JSON documents
+const OSCALMediaType = "application/json"
+
+// OSCALPropertyNamespace qualifies the properties this server adds to OSCAL
+// documents
+const OSCALPropertyNamespace = "https://github.com/xiyuan/compliance-mcp/ns/oscal"
+
+// NISTFramework is the control annotation framework of NIST SP 800-53
+// controls, the catalog OSCAL control IDs refer to
+const NISTFramework = "NIST-800-53"
+
+// oscalUUIDSpace derives the name-based UUIDs of exported documents, so
+// exporting the same scan results twice yields the same observation and
+// finding UUIDs
+var oscalUUIDSpace = uuid.NewSHA1(uuid.NameSpaceURL, []byte(OSCALPropertyNamespace))
+
+// nistEnhancement matches a NIST control enhancement such as AC-2(1)
+var nistEnhancement = regexp.MustCompile(`\s*\((\w+)\)`)
+
+// OSCALDocument is an OSCAL assessment-results document
+type OSCALDocument struct {
+	AssessmentResults OSCALAssessmentResults `json:"assessment-results"`
+}
+
+// OSCALAssessmentResults records the results of one or more assessments
+type OSCALAssessmentResults struct {
+	UUID     string        `json:"uuid"`
+	Metadata OSCALMetadata `json:"metadata"`
+	ImportAP OSCALImport   `json:"import-ap"`
+	Results  []OSCALResult `json:"results"`
+}
+
+// OSCALMetadata describes the document
+type OSCALMetadata struct {
+	Title        string          `json:"title"`
+	LastModified string          `json:"last-modified"`
+	Version      string          `json:"version"`
+	OSCALVersion string          `json:"oscal-version"`
+	Props        []OSCALProperty `json:"props,omitempty"`
+}
+
+// OSCALImport refers to another OSCAL document
+type OSCALImport struct {
+	Href string `json:"href"`
+}
+
+// OSCALResult is one assessment: what was tested, what was seen and what
+// was found
+type OSCALResult struct {
+	UUID             string                 `json:"uuid"`
+	Title            string                 `json:"title"`
+	Description      string                 `json:"description"`
+	Start            string                 `json:"start"`
+	End              string                 `json:"end,omitempty"`
+	Props            []OSCALProperty        `json:"props,omitempty"`
+	LocalDefinitions *OSCALLocalDefinitions `json:"local-definitions,omitempty"`
+	ReviewedControls OSCALReviewedControls  `json:"reviewed-controls"`
+	Observations     []OSCALObservation     `json:"observations,omitempty"`
+	Findings         []OSCALFinding         `json:"findings,omitempty"`
+}
+
+// OSCALLocalDefinitions defines the subjects observations refer to
+type OSCALLocalDefinitions struct {
+	Components     []OSCALComponent     `json:"components,omitempty"`
+	InventoryItems []OSCALInventoryItem `json:"inventory-items,omitempty"`
+}
+
+// OSCALComponent is an assessed component, here the cluster
+type OSCALComponent struct {
+	UUID        string          `json:"uuid"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Props       []OSCALProperty `json:"props,omitempty"`
+	Status      OSCALState      `json:"status"`
+}
+
+// OSCALInventoryItem is an assessed asset, here a node
+type OSCALInventoryItem struct {
+	UUID        string          `json:"uuid"`
+	Description string          `json:"description"`
+	Props       []OSCALProperty `json:"props,omitempty"`
+}
+
+// OSCALState is the state of a component or objective
+type OSCALState struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OSCALReviewedControls lists the controls an assessment covered
+type OSCALReviewedControls struct {
+	ControlSelections []OSCALControlSelection `json:"control-selections"`
+}
+
+// OSCALControlSelection selects the listed controls. OSCAL requires at
+// least one entry in include-controls, so a selection of no controls has
+// only a description.
+type OSCALControlSelection struct {
+	Description     string            `json:"description,omitempty"`
+	IncludeControls []OSCALControlRef `json:"include-controls,omitempty"`
+}
+
+// OSCALControlRef refers to a catalog control
+type OSCALControlRef struct {
+	ControlID string `json:"control-id"`
+}
+
+// OSCALObservation records the outcome of one check
+type OSCALObservation struct {
+	UUID        string            `json:"uuid"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Props       []OSCALProperty   `json:"props,omitempty"`
+	Methods     []string          `json:"methods"`
+	Subjects    []OSCALSubjectRef `json:"subjects,omitempty"`
+	Collected   string            `json:"collected"`
+}
+
+// OSCALSubjectRef refers to a component or inventory item
+type OSCALSubjectRef struct {
+	SubjectUUID string `json:"subject-uuid"`
+	Type        string `json:"type"`
+	Title       string `json:"title,omitempty"`
+}
+
+// OSCALFinding records a rule that is not satisfied
+type OSCALFinding struct {
+	UUID                string                    `json:"uuid"`
+	Title               string                    `json:"title"`
+	Description         string                    `json:"description"`
+	Props               []OSCALProperty           `json:"props,omitempty"`
+	Target              OSCALFindingTarget        `json:"target"`
+	RelatedObservations []OSCALRelatedObservation `json:"related-observations,omitempty"`
+}
+
+// OSCALFindingTarget is the control objective a finding is about
+type OSCALFindingTarget struct {
+	Type     string     `json:"type"`
+	TargetID string     `json:"target-id"`
+	Status   OSCALState `json:"status"`
+}
+
+// OSCALRelatedObservation refers to an observation behind a finding
+type OSCALRelatedObservation struct {
+	ObservationUUID string `json:"observation-uuid"`
+}
+
+// OSCALProperty is a name/value pair. Class carries the framework of
+// control properties.
+type OSCALProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	NS    string `json:"ns,omitempty"`
+	Class string `json:"class,omitempty"`
+}
+
+// OSCALControlID converts a NIST SP 800-53 control such as AC-2(1) to its
+// OSCAL catalog ID, ac-2.1
+func OSCALControlID(control string) string {
+	id := nistEnhancement.ReplaceAllString(strings.TrimSpace(control), ".$1")
+	return strings.ToLower(id)
+}
+
+// NewOSCALAssessmentResults builds an assessment-results document with one
+// result for the exported scans: an observation per check result, a finding
+// per failed rule and the nodes or the cluster as subjects. rules supplies
+// rule titles and control references; nodes lists the nodes of each Node
+// scan by scan name, and scans without nodes are attributed to the cluster.
+func NewOSCALAssessmentResults(data *ExportData, rules map[string]Rule, nodes map[string][]string, now time.Time) *OSCALDocument {
+	host := hostName(data.Host)
+	key := func(parts ...string) uuid.UUID {
+		return uuid.NewSHA1(oscalUUIDSpace, []byte(strings.Join(append([]string{host, data.Namespace}, parts...), "/")))
+	}
+
+	cluster := OSCALComponent{
+		UUID:        key("cluster").String(),
+		Type:        "this-system",
+		Title:       data.Cluster,
+		Description: fmt.Sprintf("Kubernetes cluster %s (API server %s)", data.Cluster, host),
+		Props: []OSCALProperty{
+			{Name: "asset-id", Value: host},
+		},
+		Status: OSCALState{State: "operational"},
+	}
+	definitions := &OSCALLocalDefinitions{Components: []OSCALComponent{cluster}}
+
+	result := OSCALResult{
+		UUID:             key("result", data.Title(), now.UTC().Format(time.RFC3339)).String(),
+		Title:            fmt.Sprintf("Compliance Operator %s", data.Title()),
+		Description:      fmt.Sprintf("Results of Compliance Operator %s in namespace %s of cluster %s.", data.Title(), data.Namespace, data.Cluster),
+		LocalDefinitions: definitions,
+		Props: []OSCALProperty{
+			{Name: "cluster", Value: data.Cluster, NS: OSCALPropertyNamespace},
+			{Name: "namespace", Value: data.Namespace, NS: OSCALPropertyNamespace},
+		},
+	}
+	if data.Suite != "" {
+		result.Props = append(result.Props, OSCALProperty{Name: "suite", Value: data.Suite, NS: OSCALPropertyNamespace})
+	}
+
+	var start, end time.Time
+	inventory := make(map[string]bool)
+	controls := make(map[string]bool)
+	findings := make(map[string]*OSCALFinding)
+	var failed []string
+
+	for _, export := range data.Scans {
+		scan := export.Scan
+		collected := now
+		if scan.Status.EndTimestamp != nil {
+			collected = scan.Status.EndTimestamp.Time
+		}
+		if ts := scan.Status.StartTimestamp; ts != nil && (start.IsZero() || ts.Time.Before(start)) {
+			start = ts.Time
+		}
+		if collected.After(end) {
+			end = collected
+		}
+
+		subjects := []OSCALSubjectRef{{SubjectUUID: cluster.UUID, Type: "component", Title: data.Cluster}}
+		if scan.Spec.ScanType != ScanTypePlatform && len(nodes[scan.Name]) > 0 {
+			subjects = nil
+			for _, node := range nodes[scan.Name] {
+				id := key("node", node).String()
+				subjects = append(subjects, OSCALSubjectRef{SubjectUUID: id, Type: "inventory-item", Title: node})
+				if inventory[node] {
+					continue
+				}
+				inventory[node] = true
+				definitions.InventoryItems = append(definitions.InventoryItems, OSCALInventoryItem{
+					UUID:        id,
+					Description: fmt.Sprintf("Node %s of cluster %s", node, data.Cluster),
+					Props: []OSCALProperty{
+						{Name: "asset-id", Value: node},
+						{Name: "asset-type", Value: "operating-system"},
+					},
+				})
+			}
+		}
+
+		for _, check := range export.Results {
+			rule, known := rules[check.ID]
+			title := check.Name
+			if known && rule.Title != "" {
+				title = rule.Title
+			}
+			description := check.Description
+			if description == "" {
+				description = title
+			}
+
+			observation := OSCALObservation{
+				UUID:        key("observation", check.Name, collected.UTC().Format(time.RFC3339)).String(),
+				Title:       title,
+				Description: description,
+				Methods:     []string{"TEST"},
+				Subjects:    subjects,
+				Collected:   collected.UTC().Format(time.RFC3339),
+				Props: []OSCALProperty{
+					{Name: "scan", Value: scan.Name, NS: OSCALPropertyNamespace},
+					{Name: "check-result", Value: check.Name, NS: OSCALPropertyNamespace},
+					{Name: "result", Value: XCCDFResult(check.Status), NS: OSCALPropertyNamespace},
+				},
+			}
+			if check.ID != "" {
+				observation.Props = append(observation.Props, OSCALProperty{Name: "rule-id", Value: check.ID, NS: OSCALPropertyNamespace})
+			}
+			if check.Severity != "" {
+				observation.Props = append(observation.Props, OSCALProperty{Name: "severity", Value: check.Severity, NS: OSCALPropertyNamespace})
+			}
+			observation.Props = append(observation.Props, controlProperties(rule)...)
+			result.Observations = append(result.Observations, observation)
+
+			for _, control := range rule.Controls[NISTFramework] {
+				controls[OSCALControlID(control)] = true
+			}
+
+			if check.Status != CheckFail {
+				continue
+			}
+
+			ruleKey := check.ID
+			if ruleKey == "" {
+				ruleKey = check.Name
+			}
+			finding, ok := findings[ruleKey]
+			if !ok {
+				finding = &OSCALFinding{
+					UUID:        key("finding", ruleKey, collected.UTC().Format(time.RFC3339)).String(),
+					Title:       title,
+					Description: fmt.Sprintf("Rule %s failed.", ruleKey),
+					Props: []OSCALProperty{
+						{Name: "rule-id", Value: ruleKey, NS: OSCALPropertyNamespace},
+					},
+					Target: findingTarget(rule, ruleKey),
+				}
+				if check.Severity != "" {
+					finding.Props = append(finding.Props, OSCALProperty{Name: "severity", Value: check.Severity, NS: OSCALPropertyNamespace})
+				}
+				finding.Props = append(finding.Props, controlProperties(rule)...)
+				if check.Instructions != "" {
+					finding.Description += "\n\n" + check.Instructions
+				}
+				findings[ruleKey] = finding
+				failed = append(failed, ruleKey)
+			}
+			finding.RelatedObservations = append(finding.RelatedObservations, OSCALRelatedObservation{ObservationUUID: observation.UUID})
+		}
+	}
+
+	for _, ruleKey := range failed {
+		result.Findings = append(result.Findings, *findings[ruleKey])
+	}
+
+	if start.IsZero() {
+		start = end
+	}
+	if end.IsZero() {
+		start, end = now, now
+	}
+	result.Start = start.UTC().Format(time.RFC3339)
+	result.End = end.UTC().Format(time.RFC3339)
+
+	// Without mapped controls the selection stays empty rather than
+	// claiming every control was reviewed
+	ids := make([]string, 0, len(controls))
+	for id := range controls {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	selection := OSCALControlSelection{}
+	if len(ids) == 0 {
+		selection.Description = "No assessed rule maps to a NIST 800-53 control"
+	}
+	for _, id := range ids {
+		selection.IncludeControls = append(selection.IncludeControls, OSCALControlRef{ControlID: id})
+	}
+	result.ReviewedControls.ControlSelections = []OSCALControlSelection{selection}
+
+	return &OSCALDocument{
+		AssessmentResults: OSCALAssessmentResults{
+			UUID: key("assessment-results", data.Title(), now.UTC().Format(time.RFC3339)).String(),
+			Metadata: OSCALMetadata{
+				Title:        fmt.Sprintf("Compliance Operator assessment results: %s", data.Title()),
+				LastModified: now.UTC().Format(time.RFC3339),
+				Version:      "1.0",
+				OSCALVersion: OSCALVersion,
+			},
+			// The Compliance Operator has no assessment plan document; the
+			// suite or scan that was run stands in for it
+			ImportAP: OSCALImport{Href: data.URI()},
+			Results:  []OSCALResult{result},
+		},
+	}
+}
+
+// Marshal renders the document as indented JSON
+func (d *OSCALDocument) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render OSCAL: %w", err)
+	}
+	return data, nil
+}
+
+// controlProperties lists a rule's controls as properties classed by
+// framework
+func controlProperties(rule Rule) []OSCALProperty {
+	var props []OSCALProperty
+	for _, framework := range rule.Frameworks() {
+		for _, control := range rule.Controls[framework] {
+			props = append(props, OSCALProperty{Name: "control", Value: control, NS: OSCALPropertyNamespace, Class: framework})
+		}
+	}
+	return props
+}
+
+// findingTarget targets the first NIST control of a failed rule, or the
+// rule itself when it maps to none
+func findingTarget(rule Rule, ruleKey string) OSCALFindingTarget {
+	target := OSCALFindingTarget{
+		Type:     "objective-id",
+		TargetID: ruleKey,
+		Status:   OSCALState{State: "not-satisfied"},
+	}
+	if controls := rule.Controls[NISTFramework]; len(controls) > 0 {
+		target.TargetID = OSCALControlID(controls[0]) + "_obj"
+	}
+	return target
+}
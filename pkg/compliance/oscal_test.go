@@ -0,0 +1,202 @@
+package compliance
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOSCALControlID(t *testing.T) {
+	tests := []struct {
+		control string
+		want    string
+	}{
+		{control: "AC-2", want: "ac-2"},
+		{control: "AC-2(1)", want: "ac-2.1"},
+		{control: "AC-2 (12)", want: "ac-2.12"},
+		{control: "SI-4(2)(a)", want: "si-4.2.a"},
+		{control: " CM-6 ", want: "cm-6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.control, func(t *testing.T) {
+			if got := OSCALControlID(tt.control); got != tt.want {
+				t.Errorf("OSCALControlID(%q) = %q, want %q", tt.control, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOSCALAssessmentResults(t *testing.T) {
+	now := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	mapped := map[string]Rule{
+		rulePrefix + "audit_log_path": {Title: "Configure the audit log path", Controls: map[string][]string{
+			NISTFramework: {"CM-6", "AC-2(1)"},
+			"CIS-OCP":     {"1.2.22"},
+		}},
+		rulePrefix + "kubelet_anonymous_auth": {Controls: map[string][]string{NISTFramework: {"AC-2(1)"}}},
+	}
+
+	tests := []struct {
+		name  string
+		data  *ExportData
+		rules map[string]Rule
+		nodes map[string][]string
+		// wantControls are the reviewed control IDs
+		wantControls []string
+		// wantFindings maps each finding's rule to its related observations
+		wantFindings     map[string]int
+		wantObservations int
+		wantInventory    []string
+	}{
+		{
+			name: "controls from mapped rules",
+			data: testExport(
+				testScanExport("ocp4-cis", ScanTypePlatform,
+					testCheck("ocp4-cis", "audit_log_path", CheckFail, "high"),
+					testCheck("ocp4-cis", "etcd_unique_ca", CheckPass, "medium"),
+				),
+			),
+			rules:            mapped,
+			wantControls:     []string{"ac-2.1", "cm-6"},
+			wantFindings:     map[string]int{rulePrefix + "audit_log_path": 1},
+			wantObservations: 2,
+		},
+		{
+			name: "no rule maps to NIST",
+			data: testExport(
+				testScanExport("ocp4-cis", ScanTypePlatform,
+					testCheck("ocp4-cis", "etcd_unique_ca", CheckFail, "medium"),
+				),
+			),
+			wantControls:     []string{},
+			wantFindings:     map[string]int{rulePrefix + "etcd_unique_ca": 1},
+			wantObservations: 1,
+		},
+		{
+			name: "rule failing in two scans is one finding",
+			data: testExport(
+				testScanExport("ocp4-cis-node-master", ScanTypeNode,
+					testCheck("ocp4-cis-node-master", "kubelet_anonymous_auth", CheckFail, "high"),
+				),
+				testScanExport("ocp4-cis-node-worker", ScanTypeNode,
+					testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckFail, "high"),
+					testCheck("ocp4-cis-node-worker", "file_owner_kubelet_conf", CheckManual, "medium"),
+				),
+			),
+			rules:            mapped,
+			nodes:            map[string][]string{"ocp4-cis-node-master": {"master-0", "master-1"}, "ocp4-cis-node-worker": {"worker-0"}},
+			wantControls:     []string{"ac-2.1"},
+			wantFindings:     map[string]int{rulePrefix + "kubelet_anonymous_auth": 2},
+			wantObservations: 3,
+			wantInventory:    []string{"master-0", "master-1", "worker-0"},
+		},
+		{
+			name: "check without a rule ID",
+			data: testExport(
+				testScanExport("ocp4-cis", ScanTypePlatform, ComplianceCheckResult{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis-custom-check"},
+					Status:     CheckFail,
+				}),
+			),
+			wantControls:     []string{},
+			wantFindings:     map[string]int{"ocp4-cis-custom-check": 1},
+			wantObservations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewOSCALAssessmentResults(tt.data, tt.rules, tt.nodes, now)
+			if len(doc.AssessmentResults.Results) != 1 {
+				t.Fatalf("got %d results, want 1", len(doc.AssessmentResults.Results))
+			}
+			result := doc.AssessmentResults.Results[0]
+
+			selections := result.ReviewedControls.ControlSelections
+			if len(selections) != 1 {
+				t.Fatalf("got %d control selections, want 1", len(selections))
+			}
+			controls := []string{}
+			for _, ref := range selections[0].IncludeControls {
+				controls = append(controls, ref.ControlID)
+			}
+			if !reflect.DeepEqual(controls, tt.wantControls) {
+				t.Errorf("reviewed controls = %v, want %v", controls, tt.wantControls)
+			}
+			if got := selections[0].Description != ""; got != (len(tt.wantControls) == 0) {
+				t.Errorf("selection description = %q, want one only when no control is reviewed", selections[0].Description)
+			}
+
+			findings := make(map[string]int)
+			for _, finding := range result.Findings {
+				findings[finding.Props[0].Value] = len(finding.RelatedObservations)
+				if finding.Target.Status.State != "not-satisfied" {
+					t.Errorf("finding %s state = %q, want not-satisfied", finding.Title, finding.Target.Status.State)
+				}
+			}
+			if !reflect.DeepEqual(findings, tt.wantFindings) {
+				t.Errorf("findings = %v, want %v", findings, tt.wantFindings)
+			}
+
+			if len(result.Observations) != tt.wantObservations {
+				t.Errorf("got %d observations, want %d", len(result.Observations), tt.wantObservations)
+			}
+
+			var inventory []string
+			for _, item := range result.LocalDefinitions.InventoryItems {
+				inventory = append(inventory, item.Props[0].Value)
+			}
+			if !reflect.DeepEqual(inventory, tt.wantInventory) {
+				t.Errorf("inventory = %v, want %v", inventory, tt.wantInventory)
+			}
+
+			// The same run exports to the same document
+			again := NewOSCALAssessmentResults(tt.data, tt.rules, tt.nodes, now)
+			if !reflect.DeepEqual(doc, again) {
+				t.Errorf("exporting the same data twice gave different documents")
+			}
+		})
+	}
+}
+
+func TestOSCALReviewedControlsJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules map[string]Rule
+		want  string
+	}{
+		{
+			name:  "no mapped control",
+			rules: nil,
+			want:  `{"control-selections":[{"description":"No assessed rule maps to a NIST 800-53 control"}]}`,
+		},
+		{
+			name:  "mapped control",
+			rules: map[string]Rule{rulePrefix + "etcd_unique_ca": {Controls: map[string][]string{NISTFramework: {"SC-12"}}}},
+			want:  `{"control-selections":[{"include-controls":[{"control-id":"sc-12"}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testExport(testScanExport("ocp4-cis", ScanTypePlatform, testCheck("ocp4-cis", "etcd_unique_ca", CheckPass, "medium")))
+			doc := NewOSCALAssessmentResults(data, tt.rules, nil, time.Now())
+
+			got, err := json.Marshal(doc.AssessmentResults.Results[0].ReviewedControls)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("reviewed-controls = %s, want %s", got, tt.want)
+			}
+			if strings.Contains(string(got), "include-all") || strings.Contains(string(got), `"include-controls":[]`) {
+				t.Errorf("reviewed-controls claims every control or lists none: %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,555 @@
+package compliance
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Labels and annotations the operator puts on the ConfigMaps holding each
+// scanner pod's results
+const (
+	ScanResultLabel                = "complianceoperator.openshift.io/scan-result"
+	ScanResultNodeAnnotation       = "openscap-scan-result/node"
+	ScanResultCompressedAnnotation = "openscap-scan-result/compressed"
+	scanResultDataKey              = "results"
+)
+
+// Raw result sources
+const (
+	RawResultSourceConfigMap = "configmap"
+	RawResultSourcePVC       = "pvc"
+)
+
+// rawResultsMountPath is where the extractor pod mounts the raw results PVC
+const rawResultsMountPath = "/raw-results"
+
+// rawResultMaxSize bounds a decompressed raw result file
+const rawResultMaxSize = 64 << 20
+
+// extractorFileMarker starts each file the extractor pod prints
+const extractorFileMarker = "==> "
+
+// extractorLineSlack is room in the extractor output buffer beyond the
+// longest base64 line, for the line break and marker lines with their paths
+const extractorLineSlack = 4096
+
+// extractorTruncatedMarker is printed instead of the files that would take
+// the extractor's output past extractorMaxBytes
+const extractorTruncatedMarker = "==! truncated"
+
+// extractorMaxBytes bounds the stored size of the files an extractor pod
+// prints
+const extractorMaxBytes = 64 << 20
+
+// RawResultFile is one scanner pod's raw openscap results
+type RawResultFile struct {
+	// Name is the ConfigMap name or the file's path on the PVC
+	Name string
+	// Node is the node the scanner ran on, if known
+	Node string
+	// Source is RawResultSourceConfigMap or RawResultSourcePVC
+	Source string
+	// Compressed reports whether the stored form was compressed
+	Compressed bool
+	// Content is the decompressed XCCDF or ARF document
+	Content []byte
+}
+
+// GetRawResults reads the raw results of a scan from the ConfigMaps the
+// operator stores each scanner pod's output in
+func (c *ComplianceClient) GetRawResults(ctx context.Context, scanName string) ([]RawResultFile, error) {
+	start := time.Now()
+	configMaps, err := c.kubeClient.CoreV1().ConfigMaps(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s", ScanLabel, scanName, ScanResultLabel),
+	})
+	c.logAPICall(ctx, "list", "configmaps", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list result ConfigMaps: %w", err)
+	}
+
+	var files []RawResultFile
+	for _, cm := range configMaps.Items {
+		data, ok := cm.Data[scanResultDataKey]
+		if !ok {
+			continue
+		}
+
+		compressed := cm.Annotations[ScanResultCompressedAnnotation] != ""
+		content, err := decodeRawResult([]byte(data), compressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode results in ConfigMap %s: %w", cm.Name, err)
+		}
+
+		files = append(files, RawResultFile{
+			Name:       cm.Name,
+			Node:       cm.Annotations[ScanResultNodeAnnotation],
+			Source:     RawResultSourceConfigMap,
+			Compressed: compressed,
+			Content:    content,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// Defaults for the raw results extractor pod
+const (
+	DefaultExtractorImage   = "registry.access.redhat.com/ubi9/ubi-minimal:latest"
+	DefaultExtractorTimeout = 90 * time.Second
+)
+
+// RawResultsExtractor reads raw results from a scan's PVC by running a
+// short-lived pod that mounts it read-only and prints the latest run's files
+type RawResultsExtractor struct {
+	// Image runs the extractor pod; it needs sh, wc and base64
+	Image string
+	// Timeout bounds how long the pod may take to start and finish
+	Timeout time.Duration
+}
+
+// ExtractRawResults runs an extractor pod against the scan's raw results
+// PVC, which the operator names after the scan, and returns the result files
+// of the latest run on it, and whether files were left out to keep
+// the output within extractorMaxBytes. The pod is deleted before returning.
+func (c *ComplianceClient) ExtractRawResults(ctx context.Context, scanName string, extractor RawResultsExtractor) ([]RawResultFile, bool, error) {
+	pod := extractorPod(scanName, extractor.Image)
+
+	start := time.Now()
+	created, err := c.kubeClient.CoreV1().Pods(c.namespace).Create(ctx, pod, metav1.CreateOptions{})
+	c.logAPICall(ctx, "create", "pods", start, err)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create raw results extractor pod: %w", err)
+	}
+	defer func() {
+		// Clean up even if the request was cancelled
+		deleteCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		start := time.Now()
+		err := c.kubeClient.CoreV1().Pods(c.namespace).Delete(deleteCtx, created.Name, metav1.DeleteOptions{})
+		c.logAPICall(deleteCtx, "delete", "pods", start, err)
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, extractor.Timeout)
+	defer cancel()
+
+	var phase corev1.PodPhase
+	err = wait.PollUntilContextCancel(waitCtx, time.Second, true, func(ctx context.Context) (bool, error) {
+		start := time.Now()
+		current, err := c.kubeClient.CoreV1().Pods(c.namespace).Get(ctx, created.Name, metav1.GetOptions{})
+		c.logAPICall(ctx, "get", "pods", start, err)
+		if err != nil {
+			return false, err
+		}
+		phase = current.Status.Phase
+		if err := extractorStartError(current); err != nil {
+			return false, err
+		}
+		return phase == corev1.PodSucceeded || phase == corev1.PodFailed, nil
+	})
+	var startErr *extractorStartFailure
+	if errors.As(err, &startErr) {
+		return nil, false, fmt.Errorf("raw results extractor pod %s cannot start: %w", created.Name, err)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("raw results extractor pod %s did not finish (last phase %q; the PVC may be attached to a node the pod cannot run on): %w", created.Name, phase, err)
+	}
+	if phase == corev1.PodFailed {
+		return nil, false, fmt.Errorf("raw results extractor pod %s failed; check that PVC %s exists", created.Name, scanName)
+	}
+
+	start = time.Now()
+	logs, err := c.kubeClient.CoreV1().Pods(c.namespace).GetLogs(created.Name, &corev1.PodLogOptions{}).Stream(ctx)
+	c.logAPICall(ctx, "get", "pods/log", start, err)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read raw results extractor output: %w", err)
+	}
+	defer logs.Close()
+
+	return parseExtractorOutput(logs)
+}
+
+// extractorPod builds the pod that prints the files of the latest run on a
+// scan's raw results PVC, each as a marker line with its path followed by a
+// base64 line. The operator keeps each run in a directory named after its
+// index; files are read from the top level when there is none. Once the
+// files would exceed extractorMaxBytes the truncated marker is printed
+// instead of the rest.
+func extractorPod(scanName, image string) *corev1.Pod {
+	script := fmt.Sprintf(`cd %s || exit 1
+run=
+for d in *; do
+  case "$d" in *[!0-9]*) continue ;; esac
+  [ -d "$d" ] || continue
+  if [ -z "$run" ] || [ "$d" -gt "$run" ]; then run=$d; fi
+done
+total=0
+for f in ${run:+$run/}*; do
+  [ -f "$f" ] || continue
+  total=$((total + $(wc -c < "$f")))
+  if [ "$total" -gt %d ]; then echo "%s"; break; fi
+  echo "%s$f"; base64 -w0 "$f"; echo
+done`, rawResultsMountPath, extractorMaxBytes, extractorTruncatedMarker, extractorFileMarker)
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: scanName + "-raw-extract-",
+			Labels: map[string]string{
+				ScanLabel:                      scanName,
+				"app.kubernetes.io/managed-by": "compliance-mcp",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: boolPtr(false),
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   boolPtr(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{{
+				Name:    "extract",
+				Image:   image,
+				Command: []string{"/bin/sh", "-c", script},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: boolPtr(false),
+					ReadOnlyRootFilesystem:   boolPtr(true),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "raw-results",
+					MountPath: rawResultsMountPath,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "raw-results",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: scanName,
+						ReadOnly:  true,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// extractorStartFailure is a container error the extractor pod will not
+// recover from while it is pending
+type extractorStartFailure struct {
+	reason  string
+	message string
+}
+
+func (e *extractorStartFailure) Error() string {
+	if e.reason == "CreateContainerConfigError" && strings.Contains(e.message, "runAsNonRoot") {
+		return fmt.Sprintf("%s: %s (the extractor image runs as root; use an image with a non-root USER, or a cluster that assigns pods a UID such as OpenShift)", e.reason, e.message)
+	}
+	return fmt.Sprintf("%s: %s", e.reason, e.message)
+}
+
+// extractorStartError returns why the extractor container cannot be
+// created, so the tool fails at once instead of waiting for its timeout.
+// The pod runs as non-root without naming a UID, so an image whose USER is
+// root fails here outside clusters that assign one.
+func extractorStartError(pod *corev1.Pod) error {
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName":
+				return &extractorStartFailure{reason: waiting.Reason, message: waiting.Message}
+			}
+		}
+	}
+	return nil
+}
+
+// parseExtractorOutput decodes the files printed by an extractor pod and
+// reports whether it left files out
+func parseExtractorOutput(r io.Reader) ([]RawResultFile, bool, error) {
+	// A single file may take up the whole extractorMaxBytes, and its line
+	// holds it base64-encoded
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<20), base64.StdEncoding.EncodedLen(extractorMaxBytes)+extractorLineSlack)
+
+	var (
+		files     []RawResultFile
+		truncated bool
+	)
+	name := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == extractorTruncatedMarker {
+			truncated = true
+			break
+		}
+		if strings.HasPrefix(line, extractorFileMarker) {
+			name = strings.TrimPrefix(line, extractorFileMarker)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decode %s from extractor output: %w", name, err)
+		}
+		content, err := decompress(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+
+		files = append(files, RawResultFile{
+			Name:       name,
+			Node:       nodeFromResultFile(name),
+			Source:     RawResultSourcePVC,
+			Compressed: isCompressed(raw),
+			Content:    content,
+		})
+		name = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to read extractor output: %w", err)
+	}
+
+	return files, truncated, nil
+}
+
+// nodeFromResultFile guesses the node from a PVC result file name, which
+// the operator names after the scanner pod, e.g.
+// 0/ocp4-cis-node-master-ip-10-0-1-2.ec2.internal-pod.xml.bzip2
+func nodeFromResultFile(name string) string {
+	base := path.Base(name)
+	base = strings.TrimSuffix(base, ".bzip2")
+	base = strings.TrimSuffix(base, ".xml")
+	return strings.TrimSuffix(base, "-pod")
+}
+
+// decodeRawResult decodes the results stored in a ConfigMap, which are
+// base64 encoded and compressed when the compressed annotation is set
+func decodeRawResult(data []byte, compressed bool) ([]byte, error) {
+	if !compressed {
+		return data, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return decompress(raw)
+}
+
+// Magic numbers of the compression formats raw results are stored in
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+)
+
+// isCompressed reports whether data is gzip or bzip2 compressed
+func isCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic) || bytes.HasPrefix(data, bzip2Magic)
+}
+
+// decompress inflates gzip or bzip2 data, returning anything else unchanged
+func decompress(data []byte) ([]byte, error) {
+	var reader io.Reader
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case bytes.HasPrefix(data, bzip2Magic):
+		reader = bzip2.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(reader, rawResultMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > rawResultMaxSize {
+		return nil, fmt.Errorf("decompressed results exceed %d MiB", rawResultMaxSize>>20)
+	}
+	return content, nil
+}
+
+// RuleEvidence is what a raw result file records about one rule
+type RuleEvidence struct {
+	RuleID   string
+	Result   string
+	Severity string
+	Time     string
+	// Messages are the scanner's messages about the rule
+	Messages []string
+	// Check is the OVAL definition that implements the rule, if any
+	Check string
+	// CheckResult is the OVAL definition's result
+	CheckResult string
+	// Tests lists the OVAL tests the definition evaluated with their
+	// results, e.g. "oval:ssg-test_x:tst:1 = false"
+	Tests []string
+}
+
+// RawResultSummary counts the rule results in a raw result file
+type RawResultSummary struct {
+	Rules   int
+	Results map[string]int
+}
+
+// SummarizeRawResult counts the XCCDF rule results in a document
+func SummarizeRawResult(content []byte) (RawResultSummary, error) {
+	summary := RawResultSummary{Results: make(map[string]int)}
+	evidence, err := parseRawResult(content, func(string) bool { return true })
+	if err != nil {
+		return summary, err
+	}
+	for _, rule := range evidence {
+		summary.Rules++
+		summary.Results[rule.Result]++
+	}
+	return summary, nil
+}
+
+// FindRuleEvidence returns the evidence in a result file of scanName for
+// rules matching rule, which may be a full XCCDF rule ID, a rule name or a
+// ComplianceCheckResult name
+func FindRuleEvidence(content []byte, scanName, rule string) ([]RuleEvidence, error) {
+	return parseRawResult(content, func(id string) bool { return ruleMatches(id, scanName, rule) })
+}
+
+// ruleMatches reports whether an XCCDF rule ID matches what a caller asked
+// for. Check result names are the scan name and the rule name joined with
+// dashes, so the scan name prefix is stripped before comparing the whole
+// rule name.
+func ruleMatches(id, scanName, rule string) bool {
+	if id == rule {
+		return true
+	}
+	wanted := strings.ToLower(RuleName(rule))
+	wanted = strings.TrimPrefix(wanted, strings.ToLower(scanName)+"-")
+	return RuleName(id) == strings.ReplaceAll(wanted, "-", "_")
+}
+
+// parseRawResult streams an XCCDF or ARF document, collecting the
+// rule-results whose ID matches and the OVAL definitions they reference
+func parseRawResult(content []byte, match func(string) bool) ([]RuleEvidence, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// The documents are UTF-8 but some declare it in other spellings
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	var (
+		evidence    []RuleEvidence
+		current     *RuleEvidence
+		text        strings.Builder
+		definitions = make(map[string]string)
+		tests       = make(map[string][]string)
+		definition  string
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse results document: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			text.Reset()
+			switch t.Name.Local {
+			case "rule-result":
+				id := attr(t, "idref")
+				if match(id) {
+					current = &RuleEvidence{
+						RuleID:   id,
+						Severity: attr(t, "severity"),
+						Time:     attr(t, "time"),
+					}
+				}
+			case "check-content-ref":
+				if current != nil && current.Check == "" {
+					current.Check = attr(t, "name")
+				}
+			case "definition":
+				// OVAL results record each evaluated definition with its
+				// result; the definitions section has no result attribute
+				if result := attr(t, "result"); result != "" {
+					definition = attr(t, "definition_id")
+					definitions[definition] = result
+				}
+			case "criterion":
+				if definition != "" {
+					tests[definition] = append(tests[definition], fmt.Sprintf("%s = %s", attr(t, "test_ref"), attr(t, "result")))
+				}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "result":
+				if current != nil && current.Result == "" {
+					current.Result = strings.TrimSpace(text.String())
+				}
+			case "message":
+				if current != nil {
+					if message := strings.TrimSpace(text.String()); message != "" {
+						current.Messages = append(current.Messages, message)
+					}
+				}
+			case "rule-result":
+				if current != nil {
+					evidence = append(evidence, *current)
+					current = nil
+				}
+			case "definition":
+				definition = ""
+			}
+		}
+	}
+
+	for i := range evidence {
+		if check := evidence[i].Check; check != "" {
+			evidence[i].CheckResult = definitions[check]
+			evidence[i].Tests = tests[check]
+		}
+	}
+
+	return evidence, nil
+}
+
+// attr returns the value of an attribute by local name
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
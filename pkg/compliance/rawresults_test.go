@@ -0,0 +1,365 @@
+package compliance
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRuleMatches(t *testing.T) {
+	const id = "xccdf_org.ssgproject.content_rule_api_server_tls_cipher_suites"
+
+	tests := []struct {
+		name string
+		scan string
+		rule string
+		want bool
+	}{
+		{name: "full rule ID", scan: "ocp4-cis", rule: id, want: true},
+		{name: "rule name", scan: "ocp4-cis", rule: "api_server_tls_cipher_suites", want: true},
+		{name: "rule name with dashes", scan: "ocp4-cis", rule: "api-server-tls-cipher-suites", want: true},
+		{name: "rule name in upper case", scan: "ocp4-cis", rule: "API_SERVER_TLS_CIPHER_SUITES", want: true},
+		{name: "check result name", scan: "ocp4-cis", rule: "ocp4-cis-api-server-tls-cipher-suites", want: true},
+		{name: "check result name of another scan", scan: "ocp4-cis-node-master", rule: "ocp4-cis-api-server-tls-cipher-suites", want: false},
+		{name: "longer rule ending in the name", scan: "ocp4-cis", rule: "ocp4-cis-kubelet-api-server-tls-cipher-suites", want: false},
+		{name: "rule name ending in the name", scan: "ocp4-cis", rule: "etcd_api_server_tls_cipher_suites", want: false},
+		{name: "prefix of the name", scan: "ocp4-cis", rule: "api_server_tls", want: false},
+		{name: "other rule", scan: "ocp4-cis", rule: "ocp4-cis-audit-log-forwarding-enabled", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleMatches(id, tt.scan, tt.rule); got != tt.want {
+				t.Errorf("ruleMatches(%q, %q, %q) = %t, want %t", id, tt.scan, tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+// arfDocument is a trimmed ARF report with an XCCDF result section and the
+// OVAL results behind it
+const arfDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<arf:asset-report-collection xmlns:arf="http://scap.nist.gov/schema/asset-reporting-format/1.1">
+  <arf:reports>
+    <arf:report id="xccdf1">
+      <arf:content>
+        <TestResult xmlns="http://checklists.nist.gov/xccdf/1.2">
+          <rule-result idref="xccdf_org.ssgproject.content_rule_kubelet_anonymous_auth" severity="high" time="2026-10-01T10:00:00+00:00">
+            <result>fail</result>
+            <message severity="info">anonymous auth is enabled</message>
+            <message severity="info">  </message>
+            <check system="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+              <check-content-ref name="oval:ssg-kubelet_anonymous_auth:def:1" href="#oval0"/>
+            </check>
+          </rule-result>
+          <rule-result idref="xccdf_org.ssgproject.content_rule_file_owner_kubelet_conf" severity="medium" time="2026-10-01T10:00:01+00:00">
+            <result>pass</result>
+          </rule-result>
+          <rule-result idref="xccdf_org.ssgproject.content_rule_kubelet_enable_protect_kernel_defaults" severity="medium">
+            <result>notapplicable</result>
+          </rule-result>
+        </TestResult>
+      </arf:content>
+    </arf:report>
+    <arf:report id="oval0">
+      <arf:content>
+        <oval_results xmlns="http://oval.mitre.org/XMLSchema/oval-results-5">
+          <results>
+            <system>
+              <definitions>
+                <definition definition_id="oval:ssg-kubelet_anonymous_auth:def:1" result="false" version="1">
+                  <criteria operator="AND" result="false">
+                    <criterion test_ref="oval:ssg-test_kubelet_anonymous_auth:tst:1" result="false"/>
+                  </criteria>
+                </definition>
+              </definitions>
+            </system>
+          </results>
+        </oval_results>
+      </arf:content>
+    </arf:report>
+  </arf:reports>
+</arf:asset-report-collection>
+`
+
+func TestParseRawResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		match   func(string) bool
+		want    []RuleEvidence
+		wantErr bool
+	}{
+		{
+			name:    "rule with OVAL evidence",
+			content: arfDocument,
+			match:   func(id string) bool { return RuleName(id) == "kubelet_anonymous_auth" },
+			want: []RuleEvidence{{
+				RuleID:      "xccdf_org.ssgproject.content_rule_kubelet_anonymous_auth",
+				Result:      "fail",
+				Severity:    "high",
+				Time:        "2026-10-01T10:00:00+00:00",
+				Messages:    []string{"anonymous auth is enabled"},
+				Check:       "oval:ssg-kubelet_anonymous_auth:def:1",
+				CheckResult: "false",
+				Tests:       []string{"oval:ssg-test_kubelet_anonymous_auth:tst:1 = false"},
+			}},
+		},
+		{
+			name:    "rule without a check",
+			content: arfDocument,
+			match:   func(id string) bool { return RuleName(id) == "file_owner_kubelet_conf" },
+			want: []RuleEvidence{{
+				RuleID:   "xccdf_org.ssgproject.content_rule_file_owner_kubelet_conf",
+				Result:   "pass",
+				Severity: "medium",
+				Time:     "2026-10-01T10:00:01+00:00",
+			}},
+		},
+		{
+			name:    "every rule",
+			content: arfDocument,
+			match:   func(string) bool { return true },
+			want: []RuleEvidence{
+				{
+					RuleID:      "xccdf_org.ssgproject.content_rule_kubelet_anonymous_auth",
+					Result:      "fail",
+					Severity:    "high",
+					Time:        "2026-10-01T10:00:00+00:00",
+					Messages:    []string{"anonymous auth is enabled"},
+					Check:       "oval:ssg-kubelet_anonymous_auth:def:1",
+					CheckResult: "false",
+					Tests:       []string{"oval:ssg-test_kubelet_anonymous_auth:tst:1 = false"},
+				},
+				{
+					RuleID:   "xccdf_org.ssgproject.content_rule_file_owner_kubelet_conf",
+					Result:   "pass",
+					Severity: "medium",
+					Time:     "2026-10-01T10:00:01+00:00",
+				},
+				{
+					RuleID:   "xccdf_org.ssgproject.content_rule_kubelet_enable_protect_kernel_defaults",
+					Result:   "notapplicable",
+					Severity: "medium",
+				},
+			},
+		},
+		{
+			name:    "no matching rule",
+			content: arfDocument,
+			match:   func(string) bool { return false },
+		},
+		{
+			name:    "other declared encoding",
+			content: strings.Replace(arfDocument, `encoding="UTF-8"`, `encoding="utf8"`, 1),
+			match:   func(id string) bool { return RuleName(id) == "file_owner_kubelet_conf" },
+			want: []RuleEvidence{{
+				RuleID:   "xccdf_org.ssgproject.content_rule_file_owner_kubelet_conf",
+				Result:   "pass",
+				Severity: "medium",
+				Time:     "2026-10-01T10:00:01+00:00",
+			}},
+		},
+		{
+			name:    "malformed document",
+			content: `<TestResult><rule-result idref="x"></TestResult>`,
+			match:   func(string) bool { return true },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRawResult([]byte(tt.content), tt.match)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRawResult() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRawResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	gzipped := func(size int) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(bytes.Repeat([]byte("a"), size)); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantSize int
+		wantErr  string
+	}{
+		{name: "uncompressed", data: []byte("<TestResult/>"), wantSize: len("<TestResult/>")},
+		{name: "gzip", data: gzipped(1 << 10), wantSize: 1 << 10},
+		{name: "gzip at the limit", data: gzipped(rawResultMaxSize), wantSize: rawResultMaxSize},
+		{name: "gzip over the limit", data: gzipped(rawResultMaxSize + 1), wantErr: "decompressed results exceed 64 MiB"},
+		{name: "corrupt gzip", data: append([]byte{}, gzipMagic...), wantErr: "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decompress(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decompress() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decompress() error = %v", err)
+			}
+			if len(got) != tt.wantSize {
+				t.Errorf("decompress() returned %d bytes, want %d", len(got), tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestParseExtractorOutput(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name          string
+		output        string
+		wantNames     []string
+		wantTruncated bool
+		wantErr       bool
+	}{
+		{
+			name:      "files of a run",
+			output:    "==> 3/ocp4-cis-node-worker-a-pod.xml.bzip2\n" + encode("<a/>") + "\n==> 3/ocp4-cis-node-worker-b-pod.xml.bzip2\n" + encode("<b/>") + "\n",
+			wantNames: []string{"3/ocp4-cis-node-worker-a-pod.xml.bzip2", "3/ocp4-cis-node-worker-b-pod.xml.bzip2"},
+		},
+		{
+			name:          "truncated",
+			output:        "==> 3/a.xml\n" + encode("<a/>") + "\n==! truncated\n",
+			wantNames:     []string{"3/a.xml"},
+			wantTruncated: true,
+		},
+		{
+			name:   "no files",
+			output: "",
+		},
+		{
+			name:    "corrupt base64",
+			output:  "==> 3/a.xml\n!!!\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, truncated, err := parseExtractorOutput(strings.NewReader(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExtractorOutput() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			var names []string
+			for _, file := range files {
+				names = append(names, file.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("parseExtractorOutput() files = %v, want %v", names, tt.wantNames)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("parseExtractorOutput() truncated = %t, want %t", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestExtractorStartError(t *testing.T) {
+	waiting := func(reason, message string) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		wantErr string
+	}{
+		{name: "scheduled", pod: &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}}},
+		{name: "creating", pod: waiting("ContainerCreating", "")},
+		{name: "pulling the image fails for now", pod: waiting("ImagePullBackOff", "Back-off pulling image")},
+		{
+			name:    "image runs as root",
+			pod:     waiting("CreateContainerConfigError", "container has runAsNonRoot and image will run as root"),
+			wantErr: "the extractor image runs as root",
+		},
+		{
+			name:    "invalid image",
+			pod:     waiting("InvalidImageName", `couldn't parse image name "UBI:"`),
+			wantErr: "InvalidImageName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := extractorStartError(tt.pod)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("extractorStartError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("extractorStartError() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractRawResultsStartFailure(t *testing.T) {
+	client, _ := newTestClient()
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("get", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &corev1.Pod{Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CreateContainerConfigError",
+					Message: "container has runAsNonRoot and image will run as root",
+				}},
+			}},
+		}}, nil
+	})
+	client.kubeClient = kubeClient
+
+	start := time.Now()
+	_, _, err := client.ExtractRawResults(context.Background(), "ocp4-cis", RawResultsExtractor{Image: "ubi", Timeout: time.Minute})
+	if err == nil || !strings.Contains(err.Error(), "cannot start") {
+		t.Fatalf("ExtractRawResults() error = %v, want the start failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("ExtractRawResults() took %v, want it to fail without waiting for the timeout", elapsed)
+	}
+	if pods, _ := kubeClient.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{}); len(pods.Items) != 0 {
+		t.Errorf("extractor pod was not deleted")
+	}
+}
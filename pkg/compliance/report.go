@@ -0,0 +1,232 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xiyuan/compliance-mcp/templates"
+)
+
+// ReportMediaType is the MIME type of HTML reports
+const ReportMediaType = "text/html"
+
+// reportTemplate renders a Report. It carries its own styles and charts so
+// the page can be saved or mailed as a single file.
+var reportTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"percent": percent,
+}).ParseFS(templates.FS, "report.html"))
+
+// Remediation states of a failing rule in a report
+const (
+	RemediationNone      = "none"
+	RemediationAvailable = "available"
+	RemediationApplied   = "applied"
+	RemediationPartial   = "partially applied"
+)
+
+// Report is the data of an HTML compliance report for a suite or a scan
+type Report struct {
+	Title      string
+	Cluster    string
+	Host       string
+	Namespace  string
+	Suite      string
+	Generated  time.Time
+	Counts     CheckCounts
+	Compliance float64
+	Scans      []ReportScan
+	Severities []ReportSeverity
+	// FailingRules are sorted by severity, then by how many scans they
+	// failed in
+	FailingRules []ReportRule
+}
+
+// ReportScan summarises one scan
+type ReportScan struct {
+	Name       string
+	Type       string
+	Roles      string
+	Phase      string
+	Result     string
+	Profile    string
+	Counts     CheckCounts
+	Compliance float64
+}
+
+// ReportSeverity counts check results of one severity
+type ReportSeverity struct {
+	Severity   string
+	Counts     CheckCounts
+	Compliance float64
+}
+
+// ReportRule is a rule that failed in at least one scan
+type ReportRule struct {
+	ID           string
+	Name         string
+	Severity     string
+	Description  string
+	Instructions string
+	Checks       []string
+	Scans        []string
+	Remediation  string
+}
+
+// NewReport builds a report from the exported scans
+func NewReport(data *ExportData, now time.Time) *Report {
+	report := &Report{
+		Title:     data.Title(),
+		Cluster:   data.Cluster,
+		Host:      hostName(data.Host),
+		Namespace: data.Namespace,
+		Suite:     data.Suite,
+		Generated: now.UTC(),
+	}
+
+	results := data.Results()
+	report.Counts = GetCheckCounts(results)
+	report.Compliance = CalculateCompliancePercentage(report.Counts)
+
+	bySeverity := make(map[string][]ComplianceCheckResult)
+	rules := make(map[string]*ReportRule)
+	var order []string
+	applied := make(map[string][]bool)
+
+	for i := range data.Scans {
+		export := &data.Scans[i]
+		counts := GetCheckCounts(export.Results)
+		report.Scans = append(report.Scans, ReportScan{
+			Name:       export.Scan.Name,
+			Type:       string(export.Scan.Spec.ScanType),
+			Roles:      strings.Join(ScanNodeRoles(export.Scan), ", "),
+			Phase:      string(export.Scan.Status.Phase),
+			Result:     string(export.Scan.Status.Result),
+			Profile:    ProfileName(export.Scan.Spec.Profile),
+			Counts:     counts,
+			Compliance: CalculateCompliancePercentage(counts),
+		})
+
+		for _, check := range export.Results {
+			severity := strings.ToLower(check.Severity)
+			if severity == "" {
+				severity = "unknown"
+			}
+			bySeverity[severity] = append(bySeverity[severity], check)
+
+			if check.Status != CheckFail {
+				continue
+			}
+
+			id := check.ID
+			if id == "" {
+				id = check.Name
+			}
+			rule, ok := rules[id]
+			if !ok {
+				rule = &ReportRule{
+					ID:           id,
+					Name:         RuleName(id),
+					Severity:     severity,
+					Description:  strings.TrimSpace(check.Description),
+					Instructions: strings.TrimSpace(check.Instructions),
+				}
+				rules[id] = rule
+				order = append(order, id)
+			}
+			rule.Checks = append(rule.Checks, check.Name)
+			if !containsName(rule.Scans, export.Scan.Name) {
+				rule.Scans = append(rule.Scans, export.Scan.Name)
+			}
+			for _, remediation := range export.RemediationsFor(check) {
+				applied[id] = append(applied[id], remediation.Spec.Apply)
+			}
+		}
+	}
+
+	severities := make([]string, 0, len(bySeverity))
+	for severity := range bySeverity {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool { return SeverityRank(severities[i]) > SeverityRank(severities[j]) })
+	for _, severity := range severities {
+		counts := GetCheckCounts(bySeverity[severity])
+		report.Severities = append(report.Severities, ReportSeverity{
+			Severity:   severity,
+			Counts:     counts,
+			Compliance: CalculateCompliancePercentage(counts),
+		})
+	}
+
+	for _, id := range order {
+		rule := rules[id]
+		rule.Remediation = remediationState(applied[id])
+		report.FailingRules = append(report.FailingRules, *rule)
+	}
+	sort.SliceStable(report.FailingRules, func(i, j int) bool {
+		a, b := report.FailingRules[i], report.FailingRules[j]
+		if SeverityRank(a.Severity) != SeverityRank(b.Severity) {
+			return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+		}
+		return len(a.Scans) > len(b.Scans)
+	})
+
+	return report
+}
+
+// HTML renders the report as a self-contained HTML page
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Other counts check results that are neither pass, fail, manual, error
+// nor info, such as NOT-APPLICABLE and INCONSISTENT
+func (r ReportScan) Other() int {
+	return otherCount(r.Counts)
+}
+
+// Other counts the report's check results of no listed status
+func (r *Report) Other() int {
+	return otherCount(r.Counts)
+}
+
+func otherCount(counts CheckCounts) int {
+	return counts.Total - counts.Pass - counts.Fail - counts.Manual - counts.Error - counts.Info
+}
+
+// remediationState summarises whether a rule's remediations are applied
+func remediationState(applied []bool) string {
+	if len(applied) == 0 {
+		return RemediationNone
+	}
+
+	count := 0
+	for _, apply := range applied {
+		if apply {
+			count++
+		}
+	}
+	switch count {
+	case 0:
+		return RemediationAvailable
+	case len(applied):
+		return RemediationApplied
+	default:
+		return RemediationPartial
+	}
+}
+
+// percent returns part as a percentage of total, for chart widths
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
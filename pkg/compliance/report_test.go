@@ -0,0 +1,138 @@
+package compliance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRemediationState(t *testing.T) {
+	tests := []struct {
+		applied []bool
+		want    string
+	}{
+		{applied: nil, want: RemediationNone},
+		{applied: []bool{false, false}, want: RemediationAvailable},
+		{applied: []bool{true, true}, want: RemediationApplied},
+		{applied: []bool{true, false}, want: RemediationPartial},
+	}
+
+	for _, tt := range tests {
+		if got := remediationState(tt.applied); got != tt.want {
+			t.Errorf("remediationState(%v) = %q, want %q", tt.applied, got, tt.want)
+		}
+	}
+}
+
+// testRemediation builds a remediation owned by a check result
+func testRemediation(check ComplianceCheckResult, suffix string, apply bool) ComplianceRemediation {
+	return ComplianceRemediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            check.Name + suffix,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ComplianceCheckResult", Name: check.Name}},
+		},
+		Spec: ComplianceRemediationSpec{Apply: apply},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	masterAuth := testCheck("ocp4-cis-node-master", "kubelet_anonymous_auth", CheckFail, "high")
+	workerAuth := testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckFail, "high")
+	auditLog := testCheck("ocp4-cis", "audit_log_path", CheckFail, "medium")
+
+	master := testScanExport("ocp4-cis-node-master", ScanTypeNode, masterAuth,
+		testCheck("ocp4-cis-node-master", "file_owner_kubelet_conf", CheckPass, "medium"))
+	master.Scan.Spec.NodeSelector = map[string]string{nodeRolePrefix + "master": ""}
+	master.Remediations = []ComplianceRemediation{testRemediation(masterAuth, "", true)}
+
+	worker := testScanExport("ocp4-cis-node-worker", ScanTypeNode, workerAuth)
+	worker.Scan.Spec.NodeSelector = map[string]string{nodeRolePrefix + "worker": ""}
+	worker.Remediations = []ComplianceRemediation{testRemediation(workerAuth, "-1", false)}
+
+	platform := testScanExport("ocp4-cis", ScanTypePlatform, auditLog,
+		testCheck("ocp4-cis", "etcd_unique_ca", CheckPass, ""),
+		testCheck("ocp4-cis", "api_server_encryption", CheckNotApplicable, "high"))
+
+	now := time.Date(2026, 10, 2, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	report := NewReport(testExport(platform, master, worker), now)
+
+	if report.Host != "api.prod.example.com" || !report.Generated.Equal(now) || report.Generated.Location() != time.UTC {
+		t.Errorf("host and time = %s %s, want api.prod.example.com and %s in UTC", report.Host, report.Generated, now)
+	}
+	if want := (CheckCounts{Total: 6, Pass: 2, Fail: 3}); report.Counts != want {
+		t.Errorf("counts = %+v, want %+v", report.Counts, want)
+	}
+	if report.Other() != 1 {
+		t.Errorf("other = %d, want 1", report.Other())
+	}
+
+	var roles []string
+	for _, scan := range report.Scans {
+		roles = append(roles, scan.Roles)
+	}
+	if want := []string{"", "master", "worker"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("scan roles = %v, want %v", roles, want)
+	}
+
+	var severities []string
+	for _, severity := range report.Severities {
+		severities = append(severities, severity.Severity)
+	}
+	if want := []string{"high", "medium", "unknown"}; !reflect.DeepEqual(severities, want) {
+		t.Errorf("severities = %v, want %v", severities, want)
+	}
+
+	want := []ReportRule{
+		{
+			ID:           rulePrefix + "kubelet_anonymous_auth",
+			Name:         "kubelet_anonymous_auth",
+			Severity:     "high",
+			Description:  "Check kubelet_anonymous_auth",
+			Instructions: "Run the check for kubelet_anonymous_auth",
+			Checks:       []string{masterAuth.Name, workerAuth.Name},
+			Scans:        []string{"ocp4-cis-node-master", "ocp4-cis-node-worker"},
+			Remediation:  RemediationPartial,
+		},
+		{
+			ID:           rulePrefix + "audit_log_path",
+			Name:         "audit_log_path",
+			Severity:     "medium",
+			Description:  "Check audit_log_path",
+			Instructions: "Run the check for audit_log_path",
+			Checks:       []string{auditLog.Name},
+			Scans:        []string{"ocp4-cis"},
+			Remediation:  RemediationNone,
+		},
+	}
+	if !reflect.DeepEqual(report.FailingRules, want) {
+		t.Errorf("failing rules = %+v, want %+v", report.FailingRules, want)
+	}
+}
+
+func TestReportHTML(t *testing.T) {
+	check := testCheck("ocp4-cis", "audit_log_path", CheckFail, "high")
+	check.Description = "Set <audit-log-path> & restart"
+	report := NewReport(testExport(testScanExport("ocp4-cis", ScanTypePlatform, check)), time.Now())
+
+	page, err := report.HTML()
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	html := string(page)
+
+	for _, want := range []string{
+		"<title>Compliance report: " + report.Title + "</title>",
+		"Failing rules (1)",
+		"Set &lt;audit-log-path&gt; &amp; restart",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<audit-log-path>") {
+		t.Errorf("page does not escape the check description")
+	}
+}
@@ -0,0 +1,106 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ControlAnnotationPrefix prefixes the Rule annotations that map a rule to
+// the controls of a framework, e.g.
+// control.compliance.openshift.io/NIST-800-53: "CM-6;CM-6(1)"
+const ControlAnnotationPrefix = "control.compliance.openshift.io/"
+
+// RuleGVR is the resource of the rules parsed from profile bundles
+var RuleGVR = schema.GroupVersionResource{
+	Group:    "compliance.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "rules",
+}
+
+// Rule is a rule from a profile bundle
+type Rule struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	ID                string `json:"id,omitempty"`
+	Title             string `json:"title,omitempty"`
+	Severity          string `json:"severity,omitempty"`
+	// Controls maps a framework, e.g. NIST-800-53, to the controls the rule
+	// implements
+	Controls map[string][]string `json:"controls,omitempty"`
+}
+
+// GetRules returns the rules in the namespace keyed by XCCDF rule ID, which
+// is also the ID of their check results
+func (c *ComplianceClient) GetRules(ctx context.Context) (map[string]Rule, error) {
+	start := time.Now()
+	list, err := c.dynamicClient.Resource(RuleGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	c.logAPICall(ctx, "list", "rules", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	rules := make(map[string]Rule, len(list.Items))
+	for _, item := range list.Items {
+		rule := unstructuredToRule(&item)
+		if rule.ID == "" {
+			continue
+		}
+		rules[rule.ID] = rule
+	}
+
+	return rules, nil
+}
+
+// RuleControls parses the control annotations of a rule. Controls are
+// separated by semicolons or commas.
+func RuleControls(annotations map[string]string) map[string][]string {
+	controls := make(map[string][]string)
+	for key, value := range annotations {
+		framework := strings.TrimPrefix(key, ControlAnnotationPrefix)
+		if framework == key || framework == "" {
+			continue
+		}
+
+		for _, control := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }) {
+			if control = strings.TrimSpace(control); control != "" && !containsName(controls[framework], control) {
+				controls[framework] = append(controls[framework], control)
+			}
+		}
+		sort.Strings(controls[framework])
+	}
+	return controls
+}
+
+// Frameworks returns the frameworks a rule maps to, sorted
+func (r Rule) Frameworks() []string {
+	frameworks := make([]string, 0, len(r.Controls))
+	for framework := range r.Controls {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+	return frameworks
+}
+
+func unstructuredToRule(obj *unstructured.Unstructured) Rule {
+	rule := Rule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        obj.GetName(),
+			Namespace:   obj.GetNamespace(),
+			Labels:      obj.GetLabels(),
+			Annotations: obj.GetAnnotations(),
+		},
+		Controls: RuleControls(obj.GetAnnotations()),
+	}
+
+	rule.ID, _, _ = unstructured.NestedString(obj.Object, "id")
+	rule.Title, _, _ = unstructured.NestedString(obj.Object, "title")
+	rule.Severity, _, _ = unstructured.NestedString(obj.Object, "severity")
+
+	return rule
+}
@@ -0,0 +1,264 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SARIFVersion is the SARIF version of exported logs
+const SARIFVersion = "2.1.0"
+
+// SARIFSchema is the JSON schema of SARIF 2.1.0 logs
+const SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIFMediaType is the MIME type of SARIF logs
+const SARIFMediaType = "application/sarif+json"
+
+// SARIFLog is a SARIF log with one run of the Compliance Operator
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the rules that were checked and the checks that failed
+type SARIFRun struct {
+	Tool              SARIFTool              `json:"tool"`
+	AutomationDetails SARIFAutomationDetails `json:"automationDetails"`
+	Results           []SARIFResult          `json:"results"`
+	Properties        map[string]string      `json:"properties,omitempty"`
+}
+
+// SARIFTool describes the analysis tool
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that ran the rules
+type SARIFDriver struct {
+	Name           string                     `json:"name"`
+	InformationURI string                     `json:"informationUri"`
+	Rules          []SARIFReportingDescriptor `json:"rules"`
+}
+
+// SARIFAutomationDetails identifies the run, so repeated exports of the
+// same suite are recognised as the same analysis
+type SARIFAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// SARIFReportingDescriptor describes a rule
+type SARIFReportingDescriptor struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage          `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage          `json:"fullDescription,omitempty"`
+	Help                 *SARIFMessage          `json:"help,omitempty"`
+	DefaultConfiguration SARIFRuleConfiguration `json:"defaultConfiguration"`
+	Properties           SARIFRuleProperties    `json:"properties"`
+}
+
+// SARIFRuleConfiguration is a rule's default reporting configuration
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFRuleProperties carries a rule's severity. security-severity is the
+// CVSS-like score code scanning dashboards rank security findings by.
+type SARIFRuleProperties struct {
+	Severity         string   `json:"severity"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// SARIFMessage is a plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a failed or errored check
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+// SARIFLocation locates a result at its ComplianceCheckResult
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations"`
+}
+
+// SARIFPhysicalLocation refers to an artifact by URI
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is an artifact URI
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation names the Kubernetes object a result is about
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIFLevel maps a check severity to a SARIF level
+func SARIFLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps a check severity to a security-severity score
+func sarifSecuritySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	case "low":
+		return "3.0"
+	default:
+		return ""
+	}
+}
+
+// NewSARIFLog builds a SARIF log for the exported scans. Every rule that was
+// checked becomes a reportingDescriptor; every FAIL or ERROR check result
+// becomes a result.
+func NewSARIFLog(data *ExportData) *SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{
+			Driver: SARIFDriver{
+				Name:           "OpenShift Compliance Operator",
+				InformationURI: "https://github.com/ComplianceAsCode/compliance-operator",
+				Rules:          []SARIFReportingDescriptor{},
+			},
+		},
+		AutomationDetails: SARIFAutomationDetails{
+			ID: fmt.Sprintf("compliance/%s/%s/suites/%s/", data.Cluster, data.Namespace, data.Suite),
+		},
+		Results: []SARIFResult{},
+		Properties: map[string]string{
+			"cluster":   data.Cluster,
+			"namespace": data.Namespace,
+		},
+	}
+	if data.Scan != "" {
+		run.AutomationDetails.ID = fmt.Sprintf("compliance/%s/%s/scans/%s/", data.Cluster, data.Namespace, data.Scan)
+	}
+	if data.Suite != "" {
+		run.Properties["suite"] = data.Suite
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, export := range data.Scans {
+		for _, check := range export.Results {
+			id := check.ID
+			if id == "" {
+				id = check.Name
+			}
+
+			index, ok := ruleIndex[id]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[id] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule(id, check))
+			}
+
+			if check.Status != CheckFail && check.Status != CheckError {
+				continue
+			}
+
+			message := fmt.Sprintf("Check %s failed in scan %s.", check.Name, export.Scan.Name)
+			if check.Status == CheckError {
+				message = fmt.Sprintf("Check %s could not be evaluated in scan %s.", check.Name, export.Scan.Name)
+			}
+
+			run.Results = append(run.Results, SARIFResult{
+				RuleID:    id,
+				RuleIndex: index,
+				Level:     SARIFLevel(check.Severity),
+				Message:   SARIFMessage{Text: message},
+				Locations: []SARIFLocation{{
+					PhysicalLocation: SARIFPhysicalLocation{
+						ArtifactLocation: SARIFArtifactLocation{
+							URI: ExportURI(data.Cluster, data.Namespace, "compliancecheckresults", check.Name),
+						},
+					},
+					LogicalLocations: []SARIFLogicalLocation{{
+						Name:               check.Name,
+						FullyQualifiedName: fmt.Sprintf("%s/%s/compliancecheckresults/%s", data.Cluster, data.Namespace, check.Name),
+						Kind:               "resource",
+					}},
+				}},
+				PartialFingerprints: map[string]string{
+					"complianceCheckResult/v1": fmt.Sprintf("%s/%s/%s", data.Cluster, data.Namespace, check.Name),
+				},
+				Properties: map[string]string{
+					"scan":   export.Scan.Name,
+					"status": string(check.Status),
+				},
+			})
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []SARIFRun{run},
+	}
+}
+
+// Marshal renders the log as indented JSON
+func (l *SARIFLog) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SARIF: %w", err)
+	}
+	return data, nil
+}
+
+// sarifRule describes the rule behind a check result
+func sarifRule(id string, check ComplianceCheckResult) SARIFReportingDescriptor {
+	rule := SARIFReportingDescriptor{
+		ID:   id,
+		Name: RuleName(id),
+		DefaultConfiguration: SARIFRuleConfiguration{
+			Level: SARIFLevel(check.Severity),
+		},
+		Properties: SARIFRuleProperties{
+			Severity:         strings.ToLower(check.Severity),
+			SecuritySeverity: sarifSecuritySeverity(check.Severity),
+			Tags:             []string{"security", "compliance"},
+		},
+	}
+	if rule.Properties.Severity == "" {
+		rule.Properties.Severity = "unknown"
+	}
+
+	if description := strings.TrimSpace(check.Description); description != "" {
+		title, _, _ := strings.Cut(description, "\n")
+		rule.ShortDescription = &SARIFMessage{Text: strings.TrimSpace(title)}
+		rule.FullDescription = &SARIFMessage{Text: description}
+	}
+	if instructions := strings.TrimSpace(check.Instructions); instructions != "" {
+		rule.Help = &SARIFMessage{Text: instructions}
+	}
+
+	return rule
+}
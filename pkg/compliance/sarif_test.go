@@ -0,0 +1,185 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSARIFLog(t *testing.T) {
+	// sarifResult is the part of a result the cases check
+	type sarifResult struct {
+		RuleID    string
+		RuleIndex int
+		Level     string
+		Status    string
+	}
+
+	tests := []struct {
+		name       string
+		data       *ExportData
+		wantRules  []string
+		wantLevels []string
+		// wantSecuritySeverities are the rules' security-severity scores
+		wantSecuritySeverities []string
+		wantResults            []sarifResult
+		wantAutomationID       string
+	}{
+		{
+			name: "failed and errored checks become results",
+			data: testExport(
+				testScanExport("ocp4-cis", ScanTypePlatform,
+					testCheck("ocp4-cis", "audit_log_path", CheckFail, "high"),
+					testCheck("ocp4-cis", "etcd_unique_ca", CheckError, "medium"),
+					testCheck("ocp4-cis", "scc_limit_root", CheckPass, "low"),
+					testCheck("ocp4-cis", "idp_is_configured", CheckManual, "medium"),
+					testCheck("ocp4-cis", "routes_protected", CheckInfo, ""),
+				),
+			),
+			wantRules:              []string{"audit_log_path", "etcd_unique_ca", "scc_limit_root", "idp_is_configured", "routes_protected"},
+			wantLevels:             []string{"error", "warning", "note", "warning", "note"},
+			wantSecuritySeverities: []string{"8.0", "5.5", "3.0", "5.5", ""},
+			wantResults: []sarifResult{
+				{RuleID: rulePrefix + "audit_log_path", RuleIndex: 0, Level: "error", Status: "FAIL"},
+				{RuleID: rulePrefix + "etcd_unique_ca", RuleIndex: 1, Level: "warning", Status: "ERROR"},
+			},
+			wantAutomationID: "compliance/prod/openshift-compliance/suites/cis-compliance/",
+		},
+		{
+			name: "rule checked by two scans is described once",
+			data: testExport(
+				testScanExport("ocp4-cis-node-master", ScanTypeNode,
+					testCheck("ocp4-cis-node-master", "kubelet_anonymous_auth", CheckFail, "HIGH"),
+				),
+				testScanExport("ocp4-cis-node-worker", ScanTypeNode,
+					testCheck("ocp4-cis-node-worker", "file_owner_kubelet_conf", CheckPass, "medium"),
+					testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckFail, "HIGH"),
+				),
+			),
+			wantRules:              []string{"kubelet_anonymous_auth", "file_owner_kubelet_conf"},
+			wantLevels:             []string{"error", "warning"},
+			wantSecuritySeverities: []string{"8.0", "5.5"},
+			wantResults: []sarifResult{
+				{RuleID: rulePrefix + "kubelet_anonymous_auth", RuleIndex: 0, Level: "error", Status: "FAIL"},
+				{RuleID: rulePrefix + "kubelet_anonymous_auth", RuleIndex: 0, Level: "error", Status: "FAIL"},
+			},
+			wantAutomationID: "compliance/prod/openshift-compliance/suites/cis-compliance/",
+		},
+		{
+			name: "single scan",
+			data: func() *ExportData {
+				data := testExport(testScanExport("ocp4-cis", ScanTypePlatform, testCheck("ocp4-cis", "scc_limit_root", CheckPass, "low")))
+				data.Scan = "ocp4-cis"
+				return data
+			}(),
+			wantRules:              []string{"scc_limit_root"},
+			wantLevels:             []string{"note"},
+			wantSecuritySeverities: []string{"3.0"},
+			wantResults:            []sarifResult{},
+			wantAutomationID:       "compliance/prod/openshift-compliance/scans/ocp4-cis/",
+		},
+		{
+			name:                   "no scans",
+			data:                   testExport(),
+			wantRules:              []string{},
+			wantLevels:             []string{},
+			wantSecuritySeverities: []string{},
+			wantResults:            []sarifResult{},
+			wantAutomationID:       "compliance/prod/openshift-compliance/suites/cis-compliance/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewSARIFLog(tt.data)
+			if log.Version != SARIFVersion || len(log.Runs) != 1 {
+				t.Fatalf("got version %q with %d runs, want %q with 1", log.Version, len(log.Runs), SARIFVersion)
+			}
+			run := log.Runs[0]
+
+			rules, levels, scores := []string{}, []string{}, []string{}
+			for _, rule := range run.Tool.Driver.Rules {
+				rules = append(rules, rule.Name)
+				levels = append(levels, rule.DefaultConfiguration.Level)
+				scores = append(scores, rule.Properties.SecuritySeverity)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", rules, tt.wantRules)
+			}
+			if !reflect.DeepEqual(levels, tt.wantLevels) {
+				t.Errorf("rule levels = %v, want %v", levels, tt.wantLevels)
+			}
+			if !reflect.DeepEqual(scores, tt.wantSecuritySeverities) {
+				t.Errorf("security severities = %v, want %v", scores, tt.wantSecuritySeverities)
+			}
+
+			results := []sarifResult{}
+			fingerprints := make(map[string]bool)
+			for _, result := range run.Results {
+				results = append(results, sarifResult{
+					RuleID:    result.RuleID,
+					RuleIndex: result.RuleIndex,
+					Level:     result.Level,
+					Status:    result.Properties["status"],
+				})
+				fingerprint := result.PartialFingerprints["complianceCheckResult/v1"]
+				if fingerprints[fingerprint] {
+					t.Errorf("fingerprint %q is not unique", fingerprint)
+				}
+				fingerprints[fingerprint] = true
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("results = %+v, want %+v", results, tt.wantResults)
+			}
+
+			if run.AutomationDetails.ID != tt.wantAutomationID {
+				t.Errorf("automation ID = %q, want %q", run.AutomationDetails.ID, tt.wantAutomationID)
+			}
+		})
+	}
+}
+
+func TestSARIFRuleDescriptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		description  string
+		instructions string
+		wantShort    *SARIFMessage
+		wantFull     *SARIFMessage
+		wantHelp     *SARIFMessage
+	}{
+		{
+			name:         "title is the first line",
+			description:  "Ensure the audit log path is set\n\nThe API server should log to a file.",
+			instructions: "  Run oc get apiserver  ",
+			wantShort:    &SARIFMessage{Text: "Ensure the audit log path is set"},
+			wantFull:     &SARIFMessage{Text: "Ensure the audit log path is set\n\nThe API server should log to a file."},
+			wantHelp:     &SARIFMessage{Text: "Run oc get apiserver"},
+		},
+		{
+			name:        "blank description and instructions are left out",
+			description: "  \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := testCheck("ocp4-cis", "audit_log_path", CheckFail, "")
+			check.Description = tt.description
+			check.Instructions = tt.instructions
+
+			rule := sarifRule(check.ID, check)
+			if !reflect.DeepEqual(rule.ShortDescription, tt.wantShort) {
+				t.Errorf("shortDescription = %+v, want %+v", rule.ShortDescription, tt.wantShort)
+			}
+			if !reflect.DeepEqual(rule.FullDescription, tt.wantFull) {
+				t.Errorf("fullDescription = %+v, want %+v", rule.FullDescription, tt.wantFull)
+			}
+			if !reflect.DeepEqual(rule.Help, tt.wantHelp) {
+				t.Errorf("help = %+v, want %+v", rule.Help, tt.wantHelp)
+			}
+			if rule.Properties.Severity != "unknown" {
+				t.Errorf("severity = %q, want unknown", rule.Properties.Severity)
+			}
+		})
+	}
+}
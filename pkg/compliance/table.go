@@ -0,0 +1,150 @@
+package compliance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Table media types
+const (
+	CSVMediaType = "text/csv"
+	TSVMediaType = "text/tab-separated-values"
+)
+
+// Table columns
+const (
+	ColumnSuite                = "suite"
+	ColumnScan                 = "scan"
+	ColumnNodeRole             = "node_role"
+	ColumnCheck                = "check"
+	ColumnRuleID               = "rule_id"
+	ColumnStatus               = "status"
+	ColumnSeverity             = "severity"
+	ColumnDescription          = "description"
+	ColumnInstructions         = "instructions"
+	ColumnRemediationAvailable = "remediation_available"
+	ColumnRemediationApplied   = "remediation_applied"
+)
+
+// DefaultTableColumns are the columns of a table when none are selected
+var DefaultTableColumns = []string{
+	ColumnSuite, ColumnScan, ColumnNodeRole, ColumnRuleID, ColumnStatus, ColumnSeverity,
+	ColumnDescription, ColumnRemediationAvailable, ColumnRemediationApplied,
+}
+
+// TableColumns lists every column a table may select
+var TableColumns = []string{
+	ColumnSuite, ColumnScan, ColumnNodeRole, ColumnCheck, ColumnRuleID, ColumnStatus, ColumnSeverity,
+	ColumnDescription, ColumnInstructions, ColumnRemediationAvailable, ColumnRemediationApplied,
+}
+
+// TableOptions selects the rows and columns of a results table
+type TableOptions struct {
+	// Columns defaults to DefaultTableColumns
+	Columns []string
+	// Status and Severity keep only check results with this status or
+	// severity, as compliance_check_results filters them
+	Status   string
+	Severity string
+	// TSV separates columns with tabs instead of commas
+	TSV bool
+}
+
+// ResultsTable renders one row per check result with a header row, as CSV
+// or TSV. It returns the document and the number of check results in it.
+func ResultsTable(data *ExportData, opts TableOptions) ([]byte, int, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultTableColumns
+	}
+	for _, column := range columns {
+		if !containsName(TableColumns, column) {
+			return nil, 0, fmt.Errorf("unknown column %q (columns: %s)", column, strings.Join(TableColumns, ", "))
+		}
+	}
+
+	rows := [][]string{columns}
+	for i := range data.Scans {
+		export := &data.Scans[i]
+		role := strings.Join(ScanNodeRoles(export.Scan), ",")
+
+		for _, check := range export.Results {
+			if opts.Status != "" && string(check.Status) != opts.Status {
+				continue
+			}
+			if opts.Severity != "" && check.Severity != opts.Severity {
+				continue
+			}
+
+			remediations := export.RemediationsFor(check)
+			applied := len(remediations) > 0
+			for _, remediation := range remediations {
+				applied = applied && remediation.Spec.Apply
+			}
+
+			row := make([]string, len(columns))
+			for j, column := range columns {
+				switch column {
+				case ColumnSuite:
+					row[j] = data.Suite
+				case ColumnScan:
+					row[j] = export.Scan.Name
+				case ColumnNodeRole:
+					row[j] = role
+				case ColumnCheck:
+					row[j] = check.Name
+				case ColumnRuleID:
+					row[j] = check.ID
+				case ColumnStatus:
+					row[j] = string(check.Status)
+				case ColumnSeverity:
+					row[j] = check.Severity
+				case ColumnDescription:
+					row[j] = check.Description
+				case ColumnInstructions:
+					row[j] = check.Instructions
+				case ColumnRemediationAvailable:
+					row[j] = yesNo(len(remediations) > 0)
+				case ColumnRemediationApplied:
+					row[j] = yesNo(applied)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	var buf bytes.Buffer
+	if opts.TSV {
+		writeTSV(&buf, rows)
+	} else {
+		writer := csv.NewWriter(&buf)
+		if err := writer.WriteAll(rows); err != nil {
+			return nil, 0, fmt.Errorf("failed to render CSV: %w", err)
+		}
+	}
+
+	return buf.Bytes(), len(rows) - 1, nil
+}
+
+// writeTSV writes rows as tab-separated values. TSV has no quoting, so
+// tabs and line breaks inside a field become spaces.
+func writeTSV(buf *bytes.Buffer, rows [][]string) {
+	for _, row := range rows {
+		for i, field := range row {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(strings.Join(strings.Fields(field), " "))
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
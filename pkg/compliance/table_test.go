@@ -0,0 +1,159 @@
+package compliance
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResultsTable(t *testing.T) {
+	worker := testScanExport("ocp4-cis-node-worker", ScanTypeNode,
+		testCheck("ocp4-cis-node-worker", "kubelet_anonymous_auth", CheckFail, "high"),
+		testCheck("ocp4-cis-node-worker", "file_owner_kubelet_conf", CheckPass, "medium"),
+		testCheck("ocp4-cis-node-worker", "kubelet_eviction_thresholds", CheckFail, "medium"),
+	)
+	worker.Scan.Spec.NodeSelector = map[string]string{nodeRolePrefix + "worker": ""}
+	worker.Remediations = []ComplianceRemediation{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis-node-worker-kubelet-anonymous-auth"},
+			Spec:       ComplianceRemediationSpec{Apply: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ocp4-cis-node-worker-kubelet-eviction-thresholds-1",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ComplianceCheckResult", Name: "ocp4-cis-node-worker-kubelet-eviction-thresholds"},
+				},
+			},
+			Spec: ComplianceRemediationSpec{Apply: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ocp4-cis-node-worker-kubelet-eviction-thresholds-2",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ComplianceCheckResult", Name: "ocp4-cis-node-worker-kubelet-eviction-thresholds"},
+				},
+			},
+		},
+	}
+
+	platform := testScanExport("ocp4-cis", ScanTypePlatform,
+		testCheck("ocp4-cis", "audit_log_path", CheckManual, "medium"),
+	)
+	platform.Results[0].Description = "Audit log path\tis set,\n  \"quoted\""
+
+	data := testExport(platform, worker)
+
+	tests := []struct {
+		name      string
+		opts      TableOptions
+		want      string
+		wantCount int
+		wantErr   string
+	}{
+		{
+			name: "default columns",
+			want: "suite,scan,node_role,rule_id,status,severity,description,remediation_available,remediation_applied\n" +
+				"cis-compliance,ocp4-cis,," + rulePrefix + "audit_log_path,MANUAL,medium,\"Audit log path\tis set,\n  \"\"quoted\"\"\",no,no\n" +
+				"cis-compliance,ocp4-cis-node-worker,worker," + rulePrefix + "kubelet_anonymous_auth,FAIL,high,Check kubelet_anonymous_auth,yes,yes\n" +
+				"cis-compliance,ocp4-cis-node-worker,worker," + rulePrefix + "file_owner_kubelet_conf,PASS,medium,Check file_owner_kubelet_conf,no,no\n" +
+				"cis-compliance,ocp4-cis-node-worker,worker," + rulePrefix + "kubelet_eviction_thresholds,FAIL,medium,Check kubelet_eviction_thresholds,yes,no\n",
+			wantCount: 4,
+		},
+		{
+			name: "status filter",
+			opts: TableOptions{Columns: []string{ColumnCheck, ColumnStatus}, Status: "FAIL"},
+			want: "check,status\n" +
+				"ocp4-cis-node-worker-kubelet-anonymous-auth,FAIL\n" +
+				"ocp4-cis-node-worker-kubelet-eviction-thresholds,FAIL\n",
+			wantCount: 2,
+		},
+		{
+			name: "status and severity filters",
+			opts: TableOptions{Columns: []string{ColumnCheck}, Status: "FAIL", Severity: "medium"},
+			want: "check\n" +
+				"ocp4-cis-node-worker-kubelet-eviction-thresholds\n",
+			wantCount: 1,
+		},
+		{
+			name:      "no matching rows",
+			opts:      TableOptions{Columns: []string{ColumnCheck}, Status: "ERROR"},
+			want:      "check\n",
+			wantCount: 0,
+		},
+		{
+			name: "tsv replaces tabs and line breaks",
+			opts: TableOptions{Columns: []string{ColumnScan, ColumnDescription, ColumnInstructions}, Status: "MANUAL", TSV: true},
+			want: "scan\tdescription\tinstructions\n" +
+				"ocp4-cis\tAudit log path is set, \"quoted\"\tRun the check for audit_log_path\n",
+			wantCount: 1,
+		},
+		{
+			name:    "unknown column",
+			opts:    TableOptions{Columns: []string{ColumnCheck, "owner"}},
+			wantErr: `unknown column "owner"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count, err := ResultsTable(data, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResultsTable() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResultsTable() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ResultsTable() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if count != tt.wantCount {
+				t.Errorf("ResultsTable() count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{
+			name: "plain fields",
+			rows: [][]string{{"a", "b"}, {"c", "d"}},
+			want: "a\tb\nc\td\n",
+		},
+		{
+			name: "tabs and line breaks become spaces",
+			rows: [][]string{{"one\ttwo", "three\r\nfour\nfive"}},
+			want: "one two\tthree four five\n",
+		},
+		{
+			name: "surrounding whitespace is trimmed",
+			rows: [][]string{{"  padded  ", "\t"}},
+			want: "padded\t\n",
+		},
+		{
+			name: "quotes and commas are kept",
+			rows: [][]string{{`say "hi"`, "a,b"}},
+			want: "say \"hi\"\ta,b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeTSV(&buf, tt.rows)
+			if buf.String() != tt.want {
+				t.Errorf("writeTSV() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package compliance
+
+import (
+	"time"
+)
+
+// Thresholds controls when the collector and analyzer report a problem
+type Thresholds struct {
+	// StuckRunningAfter is how long a scan may stay RUNNING before it is
+	// reported as stuck
+	StuckRunningAfter time.Duration
+	// StuckLaunchingAfter is how long a scan may stay LAUNCHING before it is
+	// reported as stuck
+	StuckLaunchingAfter time.Duration
+	// RestartWarning is the container restart count above which a pod is
+	// reported
+	RestartWarning int32
+}
+
+// DefaultThresholds returns the thresholds used when none are configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		StuckRunningAfter:   30 * time.Minute,
+		StuckLaunchingAfter: 10 * time.Minute,
+		RestartWarning:      5,
+	}
+}
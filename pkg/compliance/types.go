@@ -36,6 +36,9 @@ const (
 	CheckManual ComplianceCheckStatus = "MANUAL"
 	CheckError  ComplianceCheckStatus = "ERROR"
 	CheckInfo   ComplianceCheckStatus = "INFO"
+
+	CheckNotApplicable ComplianceCheckStatus = "NOT-APPLICABLE"
+	CheckInconsistent  ComplianceCheckStatus = "INCONSISTENT"
 )
 
 // ComplianceScanType represents the type of scan
@@ -90,6 +93,8 @@ type ComplianceScanSpec struct {
 	ScanType ComplianceScanType `json:"scanType,omitempty"`
 	Profile  string             `json:"profile,omitempty"`
 	Content  string             `json:"content,omitempty"`
+	// NodeSelector picks the nodes a Node scan runs on
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 }
 
 type ComplianceScanStatus struct {
@@ -97,6 +102,7 @@ type ComplianceScanStatus struct {
 	Result       ComplianceScanResult `json:"result,omitempty"`
 	ErrorMessage string               `json:"errorMessage,omitempty"`
 	StartTimestamp *metav1.Time       `json:"startTimestamp,omitempty"`
+	EndTimestamp   *metav1.Time       `json:"endTimestamp,omitempty"`
 	Warnings       string             `json:"warnings,omitempty"`
 }
 
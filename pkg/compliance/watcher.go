@@ -0,0 +1,70 @@
+package compliance
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchedResources are the Compliance Operator resources the watcher keeps
+// informers for
+var watchedResources = []schema.GroupVersionResource{
+	ComplianceSuiteGVR,
+	ComplianceScanGVR,
+}
+
+// Watcher runs informers for ComplianceSuites and ComplianceScans in the
+// client's namespace. Its sync state shows whether the server can list and
+// watch the operator's resources.
+type Watcher struct {
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	stopCh    chan struct{}
+}
+
+// NewWatcher creates a watcher using the client's identity and namespace.
+// Call Start to begin watching.
+func (c *ComplianceClient) NewWatcher(resync time.Duration) *Watcher {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, resync, c.namespace, nil)
+
+	informers := make(map[schema.GroupVersionResource]cache.SharedIndexInformer, len(watchedResources))
+	for _, gvr := range watchedResources {
+		informers[gvr] = factory.ForResource(gvr).Informer()
+	}
+
+	return &Watcher{
+		factory:   factory,
+		informers: informers,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start starts the informers in the background
+func (w *Watcher) Start() {
+	w.factory.Start(w.stopCh)
+}
+
+// Stop stops the informers and waits for them to exit
+func (w *Watcher) Stop() {
+	close(w.stopCh)
+	w.factory.Shutdown()
+}
+
+// CheckSynced returns an error naming the resources whose initial list has
+// not completed
+func (w *Watcher) CheckSynced() error {
+	var pending []string
+	for _, gvr := range watchedResources {
+		if !w.informers[gvr].HasSynced() {
+			pending = append(pending, gvr.Resource)
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("informers not synced: %v", pending)
+	}
+	return nil
+}
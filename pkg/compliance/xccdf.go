@@ -0,0 +1,210 @@
+package compliance
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// XCCDFNamespace is the XML namespace of XCCDF 1.2 documents
+const XCCDFNamespace = "http://checklists.nist.gov/xccdf/1.2"
+
+// XCCDFMediaType is the MIME type of XCCDF documents
+const XCCDFMediaType = "application/xml"
+
+// nodeRolePrefix is the node label prefix that marks a node's role
+const nodeRolePrefix = "node-role.kubernetes.io/"
+
+// XCCDFTestResult is an XCCDF 1.2 TestResult element, the part of an XCCDF
+// or ARF results document that records one scan of one target
+type XCCDFTestResult struct {
+	XMLName     xml.Name          `xml:"TestResult"`
+	Namespace   string            `xml:"xmlns,attr"`
+	ID          string            `xml:"id,attr"`
+	StartTime   string            `xml:"start-time,attr,omitempty"`
+	EndTime     string            `xml:"end-time,attr"`
+	Version     string            `xml:"version,attr"`
+	Title       string            `xml:"title"`
+	Profile     *XCCDFIDRef       `xml:"profile,omitempty"`
+	Target      []string          `xml:"target"`
+	TargetFacts []XCCDFFact       `xml:"target-facts>fact"`
+	RuleResults []XCCDFRuleResult `xml:"rule-result"`
+	Score       XCCDFScore        `xml:"score"`
+}
+
+// XCCDFIDRef refers to another XCCDF item by ID
+type XCCDFIDRef struct {
+	IDRef string `xml:"idref,attr"`
+}
+
+// XCCDFFact is a named fact about the scanned target
+type XCCDFFact struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XCCDFRuleResult is the outcome of one rule
+type XCCDFRuleResult struct {
+	IDRef    string `xml:"idref,attr"`
+	Severity string `xml:"severity,attr,omitempty"`
+	Time     string `xml:"time,attr,omitempty"`
+	Result   string `xml:"result"`
+	// Ident carries the ComplianceCheckResult name so results can be
+	// traced back to the cluster
+	Ident []XCCDFIdent `xml:"ident,omitempty"`
+}
+
+// XCCDFIdent is a named identifier attached to a rule result
+type XCCDFIdent struct {
+	System string `xml:"system,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// XCCDFScore is the scan's score out of maximum
+type XCCDFScore struct {
+	System  string `xml:"system,attr"`
+	Maximum string `xml:"maximum,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// XCCDFResult maps a check status to the XCCDF result value
+func XCCDFResult(status ComplianceCheckStatus) string {
+	switch status {
+	case CheckPass:
+		return "pass"
+	case CheckFail:
+		return "fail"
+	case CheckManual:
+		return "notchecked"
+	case CheckError:
+		return "error"
+	case CheckInconsistent:
+		// Nodes disagreed, so the rule has no single result; like JUnit,
+		// report it as an evaluation error rather than unknown
+		return "error"
+	case CheckInfo:
+		return "informational"
+	case CheckNotApplicable:
+		return "notapplicable"
+	default:
+		return "unknown"
+	}
+}
+
+// ScanTargets describes what a scan checked: the node roles its
+// nodeSelector picks for a Node scan, or the cluster for a Platform scan
+func ScanTargets(scan ComplianceScan, clusterHost string) []string {
+	if scan.Spec.ScanType == ScanTypePlatform {
+		return []string{hostName(clusterHost)}
+	}
+
+	var targets []string
+	for _, role := range ScanNodeRoles(scan) {
+		targets = append(targets, "nodes with role "+role)
+	}
+
+	if len(targets) == 0 {
+		return []string{"nodes of " + hostName(clusterHost)}
+	}
+	return targets
+}
+
+// ScanNodeRoles returns the node roles a scan's nodeSelector picks, sorted
+func ScanNodeRoles(scan ComplianceScan) []string {
+	var roles []string
+	for label := range scan.Spec.NodeSelector {
+		if role := strings.TrimPrefix(label, nodeRolePrefix); role != label && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// NewXCCDFTestResult builds an XCCDF TestResult for a scan's check results
+func NewXCCDFTestResult(scan ComplianceScan, results []ComplianceCheckResult, clusterHost string, now time.Time) *XCCDFTestResult {
+	endTime := now
+	if scan.Status.EndTimestamp != nil {
+		endTime = scan.Status.EndTimestamp.Time
+	}
+
+	doc := &XCCDFTestResult{
+		Namespace: XCCDFNamespace,
+		ID:        "xccdf_compliance-mcp_testresult_" + scan.Name,
+		EndTime:   endTime.UTC().Format(time.RFC3339),
+		Version:   "1.0",
+		Title:     fmt.Sprintf("Compliance Operator scan %s/%s", scan.Namespace, scan.Name),
+		Target:    ScanTargets(scan, clusterHost),
+		TargetFacts: []XCCDFFact{
+			{Name: "urn:xccdf:fact:scanner:name", Type: "string", Value: "OpenShift Compliance Operator"},
+			{Name: "urn:xccdf:fact:asset:identifier:fqdn", Type: "string", Value: hostName(clusterHost)},
+			{Name: "urn:compliance-mcp:fact:scan-type", Type: "string", Value: string(scan.Spec.ScanType)},
+			{Name: "urn:compliance-mcp:fact:scan-result", Type: "string", Value: string(scan.Status.Result)},
+		},
+	}
+	if scan.Status.StartTimestamp != nil {
+		doc.StartTime = scan.Status.StartTimestamp.UTC().Format(time.RFC3339)
+	}
+	if scan.Spec.Profile != "" {
+		doc.Profile = &XCCDFIDRef{IDRef: scan.Spec.Profile}
+	}
+
+	sorted := append([]ComplianceCheckResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for _, result := range sorted {
+		id := result.ID
+		if id == "" {
+			id = rulePrefix + result.Name
+		}
+
+		doc.RuleResults = append(doc.RuleResults, XCCDFRuleResult{
+			IDRef:    id,
+			Severity: xccdfSeverity(result.Severity),
+			Time:     doc.EndTime,
+			Result:   XCCDFResult(result.Status),
+			Ident: []XCCDFIdent{
+				{System: "https://compliance.openshift.io/compliancecheckresult", Value: result.Name},
+			},
+		})
+	}
+
+	doc.Score = XCCDFScore{
+		System:  "urn:xccdf:scoring:default",
+		Maximum: "100",
+		Value:   fmt.Sprintf("%.2f", CalculateCompliancePercentage(GetCheckCounts(results))),
+	}
+
+	return doc
+}
+
+// Marshal renders the TestResult as an indented XML document
+func (t *XCCDFTestResult) Marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render XCCDF: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// hostName returns the host part of an API server URL
+func hostName(apiServer string) string {
+	if parsed, err := url.Parse(apiServer); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return apiServer
+}
+
+// xccdfSeverity maps a check severity to the XCCDF severity enumeration
+func xccdfSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "high", "medium", "low", "info":
+		return strings.ToLower(severity)
+	default:
+		return "unknown"
+	}
+}
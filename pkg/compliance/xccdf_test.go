@@ -0,0 +1,135 @@
+package compliance
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestXCCDFResult(t *testing.T) {
+	tests := map[ComplianceCheckStatus]string{
+		CheckPass:          "pass",
+		CheckFail:          "fail",
+		CheckManual:        "notchecked",
+		CheckError:         "error",
+		CheckInfo:          "informational",
+		CheckNotApplicable: "notapplicable",
+		CheckInconsistent:  "error",
+		"SKIP":             "unknown",
+	}
+	for status, want := range tests {
+		if got := XCCDFResult(status); got != want {
+			t.Errorf("XCCDFResult(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestScanTargets(t *testing.T) {
+	const host = "https://api.example.com:6443"
+
+	tests := []struct {
+		name string
+		scan ComplianceScan
+		want []string
+	}{
+		{
+			name: "platform scan",
+			scan: ComplianceScan{Spec: ComplianceScanSpec{ScanType: ScanTypePlatform}},
+			want: []string{"api.example.com"},
+		},
+		{
+			name: "node scan with roles",
+			scan: ComplianceScan{Spec: ComplianceScanSpec{ScanType: ScanTypeNode, NodeSelector: map[string]string{
+				nodeRolePrefix + "worker": "",
+				nodeRolePrefix + "master": "",
+				"kubernetes.io/os":        "linux",
+			}}},
+			want: []string{"nodes with role master", "nodes with role worker"},
+		},
+		{
+			name: "node scan without roles",
+			scan: ComplianceScan{Spec: ComplianceScanSpec{ScanType: ScanTypeNode}},
+			want: []string{"nodes of api.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScanTargets(tt.scan, host); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewXCCDFTestResult(t *testing.T) {
+	start := metav1.NewTime(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(time.Date(2026, 10, 1, 10, 5, 0, 0, time.UTC))
+	scan := ComplianceScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis-node-worker", Namespace: testNamespace},
+		Spec: ComplianceScanSpec{
+			ScanType:     ScanTypeNode,
+			Profile:      "xccdf_org.ssgproject.content_profile_cis-node",
+			NodeSelector: map[string]string{nodeRolePrefix + "worker": ""},
+		},
+		Status: ComplianceScanStatus{Result: ResultNonCompliant, StartTimestamp: &start, EndTimestamp: &end},
+	}
+	results := []ComplianceCheckResult{
+		testCheck(scan.Name, "kubelet_anonymous_auth", CheckFail, "high"),
+		testCheck(scan.Name, "file_owner_kubelet_conf", CheckPass, "medium"),
+		testCheck(scan.Name, "kubelet_eviction_thresholds", CheckManual, "Medium"),
+		testCheck(scan.Name, "file_groupowner_etcd", CheckNotApplicable, "critical"),
+	}
+
+	doc := NewXCCDFTestResult(scan, results, "https://api.example.com:6443", time.Now())
+
+	if doc.StartTime != "2026-10-01T10:00:00Z" || doc.EndTime != "2026-10-01T10:05:00Z" {
+		t.Errorf("times = %s to %s, want the scan's start and end", doc.StartTime, doc.EndTime)
+	}
+	if doc.Profile == nil || doc.Profile.IDRef != scan.Spec.Profile {
+		t.Errorf("profile = %v, want %s", doc.Profile, scan.Spec.Profile)
+	}
+	if want := []string{"nodes with role worker"}; !reflect.DeepEqual(doc.Target, want) {
+		t.Errorf("target = %v, want %v", doc.Target, want)
+	}
+	// 1 pass of 2 pass/fail checks
+	if doc.Score.Value != "50.00" {
+		t.Errorf("score = %s, want 50.00", doc.Score.Value)
+	}
+
+	// rule results are sorted by check name
+	want := []struct{ rule, severity, result string }{
+		{"file_groupowner_etcd", "unknown", "notapplicable"},
+		{"file_owner_kubelet_conf", "medium", "pass"},
+		{"kubelet_anonymous_auth", "high", "fail"},
+		{"kubelet_eviction_thresholds", "medium", "notchecked"},
+	}
+	if len(doc.RuleResults) != len(want) {
+		t.Fatalf("got %d rule results, want %d", len(doc.RuleResults), len(want))
+	}
+	for i, w := range want {
+		got := doc.RuleResults[i]
+		if got.IDRef != rulePrefix+w.rule || got.Severity != w.severity || got.Result != w.result || got.Time != doc.EndTime {
+			t.Errorf("rule result %d = %+v, want %s %s %s", i, got, w.rule, w.severity, w.result)
+		}
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("document does not start with the XML header")
+	}
+	var parsed XCCDFTestResult
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("document does not parse: %v", err)
+	}
+	if len(parsed.RuleResults) != len(want) {
+		t.Errorf("parsed %d rule results, want %d", len(parsed.RuleResults), len(want))
+	}
+}
@@ -0,0 +1,409 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"github.com/xiyuan/compliance-mcp/pkg/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// APIVersion is the only configuration file version this server understands
+const APIVersion = "compliance-mcp/v1"
+
+// Supported transports
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+)
+
+// Environment variables read by ApplyEnv and ConfigPath
+const (
+	EnvConfig    = "COMPLIANCE_MCP_CONFIG"
+	EnvNamespace = "COMPLIANCE_NAMESPACE"
+	EnvPort      = "PORT"
+)
+
+// Config is the server configuration. It is loaded from a YAML file and
+// then overridden by environment variables and command-line flags.
+type Config struct {
+	APIVersion string           `json:"apiVersion"`
+	Server     ServerConfig     `json:"server"`
+	Auth       AuthConfig       `json:"auth"`
+	Audit      AuditConfig      `json:"audit"`
+	Compliance ComplianceConfig `json:"compliance"`
+	Clusters   ClustersConfig   `json:"clusters"`
+	Analyzer   AnalyzerConfig   `json:"analyzer"`
+	Logs       LogsConfig       `json:"logs"`
+	RawResults RawResultsConfig `json:"rawResults"`
+	Tools      ToolsConfig      `json:"tools"`
+	Metrics    MetricsConfig    `json:"metrics"`
+}
+
+// ServerConfig configures the MCP transport and tool execution
+type ServerConfig struct {
+	// Transport is stdio, http or sse
+	Transport string `json:"transport"`
+	// Port is the HTTP listen port for the http and sse transports
+	Port string    `json:"port"`
+	TLS  TLSConfig `json:"tls"`
+	// ToolTimeout is the default deadline for a tool call; zero disables it
+	ToolTimeout metav1.Duration `json:"toolTimeout"`
+	// ToolTimeouts overrides ToolTimeout for individual tools
+	ToolTimeouts map[string]metav1.Duration `json:"toolTimeouts,omitempty"`
+	// ResponseBudgetBytes caps the size of paginated tool responses
+	ResponseBudgetBytes int `json:"responseBudgetBytes"`
+	// RateLimit limits how often each client may call tools
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	// ShutdownTimeout is how long running tool calls may take to finish
+	// after SIGTERM or SIGINT before they are cancelled
+	ShutdownTimeout metav1.Duration `json:"shutdownTimeout"`
+	// MaxConcurrentHeavyTools caps how many calls to tools marked heavy
+	// (see README) run at once; zero removes the cap
+	MaxConcurrentHeavyTools int `json:"maxConcurrentHeavyTools"`
+}
+
+// RateLimitConfig is a per-client token bucket
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained call rate; zero disables limiting
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// Burst is how many calls may be made at once
+	Burst int `json:"burst"`
+}
+
+// TLSConfig configures HTTPS for the http and sse transports
+type TLSConfig struct {
+	CertFile     string `json:"certFile,omitempty"`
+	KeyFile      string `json:"keyFile,omitempty"`
+	MinVersion   string `json:"minVersion"`
+	ClientCAFile string `json:"clientCAFile,omitempty"`
+	ClientAuth   string `json:"clientAuth"`
+}
+
+// AuthConfig configures authentication of MCP clients
+type AuthConfig struct {
+	// TokenFile is a CSV file of static bearer tokens
+	TokenFile string `json:"tokenFile,omitempty"`
+	// TokenReview validates bearer tokens with the Kubernetes TokenReview API
+	TokenReview bool `json:"tokenReview"`
+	// Audiences TokenReview tokens must be valid for
+	Audiences []string `json:"audiences,omitempty"`
+	// Impersonate makes Kubernetes API calls as the authenticated caller
+	Impersonate bool `json:"impersonate"`
+}
+
+// AuditConfig configures the tool call audit log
+type AuditConfig struct {
+	// Log is stdout, stderr or a file path; empty disables the audit log
+	Log        string `json:"log,omitempty"`
+	MaxSizeMB  int64  `json:"maxSizeMB"`
+	MaxBackups int    `json:"maxBackups"`
+	// Redact lists extra argument name regexps whose values are redacted
+	Redact []string `json:"redact,omitempty"`
+}
+
+// ComplianceConfig configures where the Compliance Operator is found
+type ComplianceConfig struct {
+	// Namespace is the operator namespace used when a tool call names none
+	Namespace string `json:"namespace"`
+	// AllowedNamespaces limits the namespaces tool calls may name. Empty
+	// allows only Namespace.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// ClustersConfig lists the clusters tool calls may query. When neither
+// Kubeconfig nor Directory is set the server uses the single cluster from
+// $KUBECONFIG, ~/.kube/config or the in-cluster config.
+type ClustersConfig struct {
+	// Kubeconfig is a kubeconfig file whose contexts are clusters
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Contexts selects contexts from Kubeconfig; empty selects all
+	Contexts []string `json:"contexts,omitempty"`
+	// Directory holds one kubeconfig file per cluster, named after the file
+	Directory string `json:"directory,omitempty"`
+	// Default is the cluster used when a tool call names none. Empty means
+	// the kubeconfig's current context, or else the first cluster.
+	Default string `json:"default,omitempty"`
+}
+
+// AnalyzerConfig configures when diagnostics report a problem
+type AnalyzerConfig struct {
+	StuckRunningAfter   metav1.Duration `json:"stuckRunningAfter"`
+	StuckLaunchingAfter metav1.Duration `json:"stuckLaunchingAfter"`
+	RestartWarning      int32           `json:"restartWarning"`
+}
+
+// LogsConfig bounds the output of the compliance_logs tool
+type LogsConfig struct {
+	DefaultTailLines int64 `json:"defaultTailLines"`
+	MaxTailLines     int64 `json:"maxTailLines"`
+	MaxFindings      int   `json:"maxFindings"`
+}
+
+// RawResultsConfig configures the pod compliance_extract_raw_results runs
+// to read a scan's raw results PVC
+type RawResultsConfig struct {
+	// ExtractorImage must provide sh, wc and base64
+	ExtractorImage string `json:"extractorImage"`
+	// ExtractorTimeout bounds how long the pod may take to start and finish
+	ExtractorTimeout metav1.Duration `json:"extractorTimeout"`
+}
+
+// ToolsConfig selects which tools are served and to whom. Entries are tool
+// names or categories (status, results, logs, diagnostics, write). An empty
+// Enabled list serves every tool; Disabled is applied afterwards.
+type ToolsConfig struct {
+	Enabled  []string `json:"enabled,omitempty"`
+	Disabled []string `json:"disabled,omitempty"`
+	// ReadOnly refuses every tool that modifies the cluster
+	ReadOnly bool `json:"readOnly"`
+	// Groups restrict the tools offered to authenticated callers by group
+	Groups []ToolGroupConfig `json:"groups,omitempty"`
+	// DefaultGroupPolicy decides what callers matching none of the groups
+	// get when groups are configured: GroupPolicyDeny (no tools) or
+	// GroupPolicyAllow (every served tool)
+	DefaultGroupPolicy string `json:"defaultGroupPolicy,omitempty"`
+}
+
+// Default group policies for callers in none of the configured groups
+const (
+	GroupPolicyAllow = "allow"
+	GroupPolicyDeny  = "deny"
+)
+
+// ToolGroupConfig restricts the tools offered to members of a group
+type ToolGroupConfig struct {
+	Group string `json:"group"`
+	// Allow lists the tools members may use; empty allows every served tool
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists tools members may not use
+	Deny []string `json:"deny,omitempty"`
+}
+
+// MetricsConfig configures the Prometheus /metrics endpoint served by the
+// http and sse transports
+type MetricsConfig struct {
+	Enabled bool `json:"enabled"`
+	// PostureInterval is how often compliance data is collected for the
+	// posture gauges
+	PostureInterval metav1.Duration `json:"postureInterval"`
+}
+
+// Default returns the configuration used when no file, environment
+// variable or flag sets a value. Settings the server's packages also use on
+// their own take those packages' defaults.
+func Default() *Config {
+	thresholds := compliance.DefaultThresholds()
+	logLimits := mcp.DefaultLogLimits()
+
+	return &Config{
+		APIVersion: APIVersion,
+		Server: ServerConfig{
+			Transport: TransportHTTP,
+			Port:      "8350",
+			TLS: TLSConfig{
+				MinVersion: "1.2",
+				ClientAuth: "require",
+			},
+			ToolTimeout:         metav1.Duration{Duration: mcp.DefaultToolTimeout},
+			ResponseBudgetBytes: mcp.DefaultResponseBudget,
+			RateLimit: RateLimitConfig{
+				RequestsPerSecond: 2,
+				Burst:             10,
+			},
+			MaxConcurrentHeavyTools: 4,
+			ShutdownTimeout:         metav1.Duration{Duration: 30 * time.Second},
+		},
+		Audit: AuditConfig{
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+		},
+		Compliance: ComplianceConfig{
+			Namespace: "openshift-compliance",
+		},
+		Analyzer: AnalyzerConfig{
+			StuckRunningAfter:   metav1.Duration{Duration: thresholds.StuckRunningAfter},
+			StuckLaunchingAfter: metav1.Duration{Duration: thresholds.StuckLaunchingAfter},
+			RestartWarning:      thresholds.RestartWarning,
+		},
+		Logs: LogsConfig{
+			DefaultTailLines: logLimits.DefaultTailLines,
+			MaxTailLines:     logLimits.MaxTailLines,
+			MaxFindings:      logLimits.MaxFindings,
+		},
+		RawResults: RawResultsConfig{
+			ExtractorImage:   compliance.DefaultExtractorImage,
+			ExtractorTimeout: metav1.Duration{Duration: compliance.DefaultExtractorTimeout},
+		},
+		Tools: ToolsConfig{
+			ReadOnly:           true,
+			DefaultGroupPolicy: GroupPolicyDeny,
+		},
+		Metrics: MetricsConfig{
+			Enabled:         true,
+			PostureInterval: metav1.Duration{Duration: time.Minute},
+		},
+	}
+}
+
+// Load reads a configuration file on top of the defaults. An empty path
+// returns the defaults.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Check the version before decoding so a future format is reported as
+	// such rather than as unknown fields
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if header.APIVersion != APIVersion {
+		return nil, fmt.Errorf("config file %s has apiVersion %q, expected %q", path, header.APIVersion, APIVersion)
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// ApplyEnv overrides the configuration with the environment variables the
+// server has always honoured
+func (c *Config) ApplyEnv() {
+	if namespace := os.Getenv(EnvNamespace); namespace != "" {
+		c.Compliance.Namespace = namespace
+	}
+	if port := os.Getenv(EnvPort); port != "" {
+		c.Server.Port = port
+	}
+}
+
+// Validate reports the first problem with the configuration
+func (c *Config) Validate() error {
+	switch c.Server.Transport {
+	case TransportStdio, TransportHTTP, TransportSSE:
+	default:
+		return fmt.Errorf("invalid server.transport %q (must be one of: stdio, http, sse)", c.Server.Transport)
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid server.port %q", c.Server.Port)
+	}
+
+	if c.Server.ToolTimeout.Duration < 0 {
+		return fmt.Errorf("server.toolTimeout must not be negative")
+	}
+	for tool, timeout := range c.Server.ToolTimeouts {
+		if timeout.Duration < 0 {
+			return fmt.Errorf("server.toolTimeouts.%s must not be negative", tool)
+		}
+	}
+	if c.Server.ResponseBudgetBytes < 1024 {
+		return fmt.Errorf("server.responseBudgetBytes must be at least 1024")
+	}
+
+	if c.Server.RateLimit.RequestsPerSecond < 0 {
+		return fmt.Errorf("server.rateLimit.requestsPerSecond must not be negative")
+	}
+	if c.Server.RateLimit.RequestsPerSecond > 0 && c.Server.RateLimit.Burst < 1 {
+		return fmt.Errorf("server.rateLimit.burst must be at least 1")
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		return fmt.Errorf("server.shutdownTimeout must be positive")
+	}
+	if c.Server.MaxConcurrentHeavyTools < 0 {
+		return fmt.Errorf("server.maxConcurrentHeavyTools must not be negative")
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		return fmt.Errorf("server.tls.certFile and server.tls.keyFile must be set together")
+	}
+	if tls.ClientCAFile != "" && tls.CertFile == "" {
+		return fmt.Errorf("server.tls.clientCAFile requires server.tls.certFile and server.tls.keyFile")
+	}
+
+	stdio := c.Server.Transport == TransportStdio
+	if stdio && (tls.CertFile != "" || c.Auth.TokenFile != "" || c.Auth.TokenReview) {
+		return fmt.Errorf("server.tls, auth.tokenFile and auth.tokenReview only apply to the http and sse transports")
+	}
+	if c.Auth.Impersonate && c.Auth.TokenFile == "" && !c.Auth.TokenReview {
+		return fmt.Errorf("auth.impersonate requires auth.tokenFile or auth.tokenReview")
+	}
+
+	if c.Audit.Log == "stdout" && stdio {
+		return fmt.Errorf("audit.log stdout cannot be used with the stdio transport, which reserves stdout for the protocol")
+	}
+	if c.Audit.MaxSizeMB < 0 || c.Audit.MaxBackups < 0 {
+		return fmt.Errorf("audit.maxSizeMB and audit.maxBackups must not be negative")
+	}
+
+	if c.Compliance.Namespace == "" {
+		return fmt.Errorf("compliance.namespace must be set")
+	}
+
+	registry := c.Clusters.Kubeconfig != "" || c.Clusters.Directory != ""
+	if len(c.Clusters.Contexts) > 0 && c.Clusters.Kubeconfig == "" {
+		return fmt.Errorf("clusters.contexts requires clusters.kubeconfig")
+	}
+	if c.Clusters.Default != "" && !registry {
+		return fmt.Errorf("clusters.default requires clusters.kubeconfig or clusters.directory")
+	}
+
+	if c.Analyzer.StuckRunningAfter.Duration <= 0 || c.Analyzer.StuckLaunchingAfter.Duration <= 0 {
+		return fmt.Errorf("analyzer.stuckRunningAfter and analyzer.stuckLaunchingAfter must be positive")
+	}
+	if c.Analyzer.RestartWarning < 0 {
+		return fmt.Errorf("analyzer.restartWarning must not be negative")
+	}
+
+	if c.Logs.DefaultTailLines <= 0 || c.Logs.MaxTailLines <= 0 || c.Logs.MaxFindings <= 0 {
+		return fmt.Errorf("logs.defaultTailLines, logs.maxTailLines and logs.maxFindings must be positive")
+	}
+	if c.Logs.DefaultTailLines > c.Logs.MaxTailLines {
+		return fmt.Errorf("logs.defaultTailLines (%d) exceeds logs.maxTailLines (%d)", c.Logs.DefaultTailLines, c.Logs.MaxTailLines)
+	}
+
+	if c.RawResults.ExtractorImage == "" {
+		return fmt.Errorf("rawResults.extractorImage must be set")
+	}
+	if c.RawResults.ExtractorTimeout.Duration <= 0 {
+		return fmt.Errorf("rawResults.extractorTimeout must be positive")
+	}
+
+	for i, rule := range c.Tools.Groups {
+		if rule.Group == "" {
+			return fmt.Errorf("tools.groups[%d].group must be set", i)
+		}
+	}
+	switch c.Tools.DefaultGroupPolicy {
+	case GroupPolicyAllow, GroupPolicyDeny:
+	default:
+		return fmt.Errorf("invalid tools.defaultGroupPolicy %q (must be %s or %s)", c.Tools.DefaultGroupPolicy, GroupPolicyAllow, GroupPolicyDeny)
+	}
+	if len(c.Tools.Groups) > 0 && c.Auth.TokenFile == "" && !c.Auth.TokenReview {
+		return fmt.Errorf("tools.groups requires authentication, since callers are matched by their groups")
+	}
+
+	if c.Metrics.PostureInterval.Duration <= 0 {
+		return fmt.Errorf("metrics.postureInterval must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		// wantErr is a substring of the expected error, empty for none
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(c *Config) {},
+		},
+		{
+			name:    "unknown transport",
+			modify:  func(c *Config) { c.Server.Transport = "grpc" },
+			wantErr: `invalid server.transport "grpc"`,
+		},
+		{
+			name:    "port out of range",
+			modify:  func(c *Config) { c.Server.Port = "70000" },
+			wantErr: `invalid server.port "70000"`,
+		},
+		{
+			name:    "port not a number",
+			modify:  func(c *Config) { c.Server.Port = "http" },
+			wantErr: `invalid server.port "http"`,
+		},
+		{
+			name:    "negative tool timeout",
+			modify:  func(c *Config) { c.Server.ToolTimeout = metav1.Duration{Duration: -time.Second} },
+			wantErr: "server.toolTimeout must not be negative",
+		},
+		{
+			name: "negative per-tool timeout",
+			modify: func(c *Config) {
+				c.Server.ToolTimeouts = map[string]metav1.Duration{"compliance_report": {Duration: -time.Second}}
+			},
+			wantErr: "server.toolTimeouts.compliance_report must not be negative",
+		},
+		{
+			name:    "response budget too small",
+			modify:  func(c *Config) { c.Server.ResponseBudgetBytes = 512 },
+			wantErr: "server.responseBudgetBytes must be at least 1024",
+		},
+		{
+			name:    "rate limit without burst",
+			modify:  func(c *Config) { c.Server.RateLimit.Burst = 0 },
+			wantErr: "server.rateLimit.burst must be at least 1",
+		},
+		{
+			name: "rate limit disabled without burst",
+			modify: func(c *Config) {
+				c.Server.RateLimit.RequestsPerSecond = 0
+				c.Server.RateLimit.Burst = 0
+			},
+		},
+		{
+			name:    "negative heavy tool cap",
+			modify:  func(c *Config) { c.Server.MaxConcurrentHeavyTools = -1 },
+			wantErr: "server.maxConcurrentHeavyTools must not be negative",
+		},
+		{
+			name:    "zero shutdown timeout",
+			modify:  func(c *Config) { c.Server.ShutdownTimeout = metav1.Duration{} },
+			wantErr: "server.shutdownTimeout must be positive",
+		},
+		{
+			name:    "certificate without key",
+			modify:  func(c *Config) { c.Server.TLS.CertFile = "tls.crt" },
+			wantErr: "server.tls.certFile and server.tls.keyFile must be set together",
+		},
+		{
+			name:    "client CA without certificate",
+			modify:  func(c *Config) { c.Server.TLS.ClientCAFile = "ca.crt" },
+			wantErr: "server.tls.clientCAFile requires server.tls.certFile and server.tls.keyFile",
+		},
+		{
+			name: "authentication over stdio",
+			modify: func(c *Config) {
+				c.Server.Transport = TransportStdio
+				c.Auth.TokenFile = "tokens.csv"
+			},
+			wantErr: "server.tls, auth.tokenFile and auth.tokenReview only apply to the http and sse transports",
+		},
+		{
+			name:    "impersonation without authentication",
+			modify:  func(c *Config) { c.Auth.Impersonate = true },
+			wantErr: "auth.impersonate requires auth.tokenFile or auth.tokenReview",
+		},
+		{
+			name: "audit log on stdout over stdio",
+			modify: func(c *Config) {
+				c.Server.Transport = TransportStdio
+				c.Audit.Log = "stdout"
+			},
+			wantErr: "audit.log stdout cannot be used with the stdio transport",
+		},
+		{
+			name:    "negative audit log size",
+			modify:  func(c *Config) { c.Audit.MaxSizeMB = -1 },
+			wantErr: "audit.maxSizeMB and audit.maxBackups must not be negative",
+		},
+		{
+			name:    "empty namespace",
+			modify:  func(c *Config) { c.Compliance.Namespace = "" },
+			wantErr: "compliance.namespace must be set",
+		},
+		{
+			name:    "contexts without kubeconfig",
+			modify:  func(c *Config) { c.Clusters.Contexts = []string{"prod"} },
+			wantErr: "clusters.contexts requires clusters.kubeconfig",
+		},
+		{
+			name:    "default cluster without registry",
+			modify:  func(c *Config) { c.Clusters.Default = "prod" },
+			wantErr: "clusters.default requires clusters.kubeconfig or clusters.directory",
+		},
+		{
+			name:    "empty extractor image",
+			modify:  func(c *Config) { c.RawResults.ExtractorImage = "" },
+			wantErr: "rawResults.extractorImage must be set",
+		},
+		{
+			name:    "zero stuck scan threshold",
+			modify:  func(c *Config) { c.Analyzer.StuckRunningAfter = metav1.Duration{} },
+			wantErr: "analyzer.stuckRunningAfter and analyzer.stuckLaunchingAfter must be positive",
+		},
+		{
+			name:    "zero max findings",
+			modify:  func(c *Config) { c.Logs.MaxFindings = 0 },
+			wantErr: "logs.defaultTailLines, logs.maxTailLines and logs.maxFindings must be positive",
+		},
+		{
+			name: "default tail lines over the maximum",
+			modify: func(c *Config) {
+				c.Logs.DefaultTailLines = 200
+				c.Logs.MaxTailLines = 100
+			},
+			wantErr: "logs.defaultTailLines (200) exceeds logs.maxTailLines (100)",
+		},
+		{
+			name:    "tool group without a name",
+			modify:  func(c *Config) { c.Tools.Groups = []ToolGroupConfig{{}} },
+			wantErr: "tools.groups[0].group must be set",
+		},
+		{
+			name: "tool groups without authentication",
+			modify: func(c *Config) {
+				c.Tools.Groups = []ToolGroupConfig{{Group: "viewers"}}
+			},
+			wantErr: "tools.groups requires authentication",
+		},
+		{
+			name: "tool groups with authentication",
+			modify: func(c *Config) {
+				c.Auth.TokenFile = "tokens.csv"
+				c.Tools.Groups = []ToolGroupConfig{{Group: "viewers"}}
+			},
+		},
+		{
+			name:   "allow callers outside the groups",
+			modify: func(c *Config) { c.Tools.DefaultGroupPolicy = GroupPolicyAllow },
+		},
+		{
+			name:    "unknown default group policy",
+			modify:  func(c *Config) { c.Tools.DefaultGroupPolicy = "maybe" },
+			wantErr: `invalid tools.defaultGroupPolicy "maybe"`,
+		},
+		{
+			name:    "zero posture interval",
+			modify:  func(c *Config) { c.Metrics.PostureInterval = metav1.Duration{} },
+			wantErr: "metrics.postureInterval must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("Validate() = nil, want an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, c *Config)
+		wantErr string
+	}{
+		{
+			name: "overrides the defaults",
+			content: "apiVersion: " + APIVersion + "\n" +
+				"server:\n" +
+				"  port: \"9090\"\n" +
+				"  toolTimeout: 30s\n" +
+				"compliance:\n" +
+				"  namespace: compliance\n",
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Port != "9090" || c.Server.ToolTimeout.Duration != 30*time.Second || c.Compliance.Namespace != "compliance" {
+					t.Errorf("got port %s, tool timeout %s, namespace %s", c.Server.Port, c.Server.ToolTimeout.Duration, c.Compliance.Namespace)
+				}
+				if c.Server.Transport != Default().Server.Transport {
+					t.Errorf("transport = %s, want the default", c.Server.Transport)
+				}
+			},
+		},
+		{
+			name:    "missing apiVersion",
+			content: "server:\n  port: \"9090\"\n",
+			wantErr: `has apiVersion "", expected "` + APIVersion + `"`,
+		},
+		{
+			name:    "future apiVersion",
+			content: "apiVersion: compliance-mcp/v2\nserver:\n  listen: \":9090\"\n",
+			wantErr: `has apiVersion "compliance-mcp/v2"`,
+		},
+		{
+			name:    "unknown field",
+			content: "apiVersion: " + APIVersion + "\nserver:\n  prot: \"9090\"\n",
+			wantErr: `unknown field "prot"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		want string
+	}{
+		{name: "separate value", args: []string{"--port", "8080", "--config", "a.yaml"}, want: "a.yaml"},
+		{name: "equals value", args: []string{"-config=a.yaml"}, want: "a.yaml"},
+		{name: "flag wins over the environment", args: []string{"--config=a.yaml"}, env: "b.yaml", want: "a.yaml"},
+		{name: "environment", args: []string{"--port", "8080"}, env: "b.yaml", want: "b.yaml"},
+		{name: "after the terminator", args: []string{"--", "--config", "a.yaml"}, want: ""},
+		{name: "similar flag", args: []string{"--config-dir", "a"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvConfig, tt.env)
+			if got := ConfigPath(tt.args); got != tt.want {
+				t.Errorf("ConfigPath(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "apiVersion: " + APIVersion + "\n" +
+		"server:\n" +
+		"  port: \"9090\"\n" +
+		"  transport: sse\n" +
+		"compliance:\n" +
+		"  namespace: from-file\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvNamespace, "from-env")
+	t.Setenv(EnvPort, "7070")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.ApplyEnv()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.BindFlags(fs)
+	if err := fs.Parse([]string{"--config", path, "--port", "6060"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// The file sets the transport, the environment the namespace and the
+	// flag the port
+	if cfg.Server.Transport != TransportSSE || cfg.Compliance.Namespace != "from-env" || cfg.Server.Port != "6060" {
+		t.Errorf("got transport %s, namespace %s, port %s, want sse, from-env, 6060",
+			cfg.Server.Transport, cfg.Compliance.Namespace, cfg.Server.Port)
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigPath returns the configuration file named by --config in args, or
+// by the COMPLIANCE_MCP_CONFIG environment variable. It is read before the
+// other flags are parsed so the file's values become the flag defaults.
+func ConfigPath(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return os.Getenv(EnvConfig)
+}
+
+// BindFlags registers a command-line flag for every setting, defaulting to
+// the value already in the configuration so flags override the file and
+// environment
+func (c *Config) BindFlags(fs *flag.FlagSet) {
+	fs.String("config", "", "YAML configuration file (apiVersion "+APIVersion+"); also read from $"+EnvConfig)
+
+	fs.StringVar(&c.Server.Transport, "transport", c.Server.Transport, "MCP transport to serve: stdio, http or sse")
+	fs.StringVar(&c.Server.Port, "port", c.Server.Port, "HTTP listen port for the http and sse transports (also $"+EnvPort+")")
+	fs.DurationVar(&c.Server.ToolTimeout.Duration, "tool-timeout", c.Server.ToolTimeout.Duration, "Default deadline for a tool call (0 disables)")
+	fs.Var((*durationMap)(&c.Server.ToolTimeouts), "tool-timeouts", "Per-tool deadlines as tool=duration pairs, e.g. compliance_diagnose=5m,compliance_logs=30s")
+	fs.Float64Var(&c.Server.RateLimit.RequestsPerSecond, "rate-limit", c.Server.RateLimit.RequestsPerSecond, "Tool calls per second allowed for each client (0 disables rate limiting)")
+	fs.IntVar(&c.Server.RateLimit.Burst, "rate-limit-burst", c.Server.RateLimit.Burst, "Tool calls each client may make at once before --rate-limit applies")
+	fs.IntVar(&c.Server.MaxConcurrentHeavyTools, "max-concurrent-heavy-tools", c.Server.MaxConcurrentHeavyTools, "Maximum calls to tools marked heavy (see README) running at once (0 removes the cap)")
+	fs.DurationVar(&c.Server.ShutdownTimeout.Duration, "shutdown-timeout", c.Server.ShutdownTimeout.Duration, "How long running tool calls may take to finish on SIGTERM or SIGINT before they are cancelled")
+	fs.IntVar(&c.Server.ResponseBudgetBytes, "response-budget-bytes", c.Server.ResponseBudgetBytes, "Maximum size in bytes of a paginated tool response")
+
+	fs.StringVar(&c.Auth.TokenFile, "auth-token-file", c.Auth.TokenFile, "Require bearer tokens listed in this CSV file (token,user,uid,\"group1,group2\")")
+	fs.BoolVar(&c.Auth.TokenReview, "auth-token-review", c.Auth.TokenReview, "Require bearer tokens validated by the Kubernetes TokenReview API")
+	fs.Var((*stringList)(&c.Auth.Audiences), "auth-audiences", "Comma-separated audiences TokenReview tokens must be valid for")
+	fs.BoolVar(&c.Auth.Impersonate, "impersonate", c.Auth.Impersonate, "Make Kubernetes API calls as the authenticated caller instead of the server's identity (requires authentication)")
+
+	fs.StringVar(&c.Server.TLS.CertFile, "tls-cert-file", c.Server.TLS.CertFile, "Serve HTTPS with this certificate (reloaded when rotated)")
+	fs.StringVar(&c.Server.TLS.KeyFile, "tls-key-file", c.Server.TLS.KeyFile, "Private key for --tls-cert-file")
+	fs.StringVar(&c.Server.TLS.MinVersion, "tls-min-version", c.Server.TLS.MinVersion, "Minimum TLS version: 1.2 or 1.3")
+	fs.StringVar(&c.Server.TLS.ClientCAFile, "tls-client-ca-file", c.Server.TLS.ClientCAFile, "Verify client certificates against this CA bundle (mutual TLS); reloaded when the file changes")
+	fs.StringVar(&c.Server.TLS.ClientAuth, "tls-client-auth", c.Server.TLS.ClientAuth, "Client certificate policy with --tls-client-ca-file: require (every endpoint but the health probes needs a verified certificate) or verify-if-given")
+
+	fs.StringVar(&c.Audit.Log, "audit-log", c.Audit.Log, "Record every tool call as JSON lines to stdout, stderr or a file path")
+	fs.Int64Var(&c.Audit.MaxSizeMB, "audit-max-size-mb", c.Audit.MaxSizeMB, "Rotate the audit log file when it reaches this size in MiB (0 disables rotation)")
+	fs.IntVar(&c.Audit.MaxBackups, "audit-max-backups", c.Audit.MaxBackups, "Number of rotated audit log files to keep")
+	fs.Var((*stringList)(&c.Audit.Redact), "audit-redact", "Comma-separated extra argument name regexps whose values are redacted from the audit log")
+
+	fs.StringVar(&c.Compliance.Namespace, "namespace", c.Compliance.Namespace, "Namespace where the Compliance Operator is installed (also $"+EnvNamespace+")")
+	fs.Var((*stringList)(&c.Compliance.AllowedNamespaces), "allowed-namespaces", "Comma-separated namespaces tool calls may name in addition to --namespace")
+
+	fs.StringVar(&c.Clusters.Kubeconfig, "clusters-kubeconfig", c.Clusters.Kubeconfig, "Serve every context of this kubeconfig file as a cluster")
+	fs.Var((*stringList)(&c.Clusters.Contexts), "cluster-contexts", "Comma-separated contexts of --clusters-kubeconfig to serve (default all)")
+	fs.StringVar(&c.Clusters.Directory, "clusters-dir", c.Clusters.Directory, "Serve each kubeconfig file in this directory as a cluster named after the file")
+	fs.StringVar(&c.Clusters.Default, "default-cluster", c.Clusters.Default, "Cluster used when a tool call names none")
+
+	fs.DurationVar(&c.Analyzer.StuckRunningAfter.Duration, "stuck-running-after", c.Analyzer.StuckRunningAfter.Duration, "Report scans RUNNING for longer than this as stuck")
+	fs.DurationVar(&c.Analyzer.StuckLaunchingAfter.Duration, "stuck-launching-after", c.Analyzer.StuckLaunchingAfter.Duration, "Report scans LAUNCHING for longer than this as stuck")
+	fs.Var(int32Value{&c.Analyzer.RestartWarning}, "restart-warning", "Report pods whose containers restarted more than this many times")
+
+	fs.Int64Var(&c.Logs.DefaultTailLines, "log-default-tail-lines", c.Logs.DefaultTailLines, "Log lines compliance_logs fetches when the caller doesn't say")
+	fs.Int64Var(&c.Logs.MaxTailLines, "log-max-tail-lines", c.Logs.MaxTailLines, "Maximum log lines compliance_logs fetches per pod")
+	fs.IntVar(&c.Logs.MaxFindings, "log-max-findings", c.Logs.MaxFindings, "Maximum errors and warnings compliance_logs reports per pod")
+
+	fs.StringVar(&c.RawResults.ExtractorImage, "raw-results-image", c.RawResults.ExtractorImage, "Image of the pod that reads a scan's raw results PVC (needs sh, wc and base64)")
+	fs.DurationVar(&c.RawResults.ExtractorTimeout.Duration, "raw-results-timeout", c.RawResults.ExtractorTimeout.Duration, "How long the raw results extractor pod may take to start and finish")
+
+	fs.Var((*stringList)(&c.Tools.Enabled), "enabled-tools", "Comma-separated tools or categories to serve (default all)")
+	fs.Var((*stringList)(&c.Tools.Disabled), "disabled-tools", "Comma-separated tools or categories not to serve")
+	fs.BoolVar(&c.Tools.ReadOnly, "read-only", c.Tools.ReadOnly, "Refuse every tool that modifies the cluster")
+	fs.StringVar(&c.Tools.DefaultGroupPolicy, "tool-default-group-policy", c.Tools.DefaultGroupPolicy, "With tools.groups configured, what callers in none of the groups get: deny (no tools) or allow (every served tool)")
+
+	fs.BoolVar(&c.Metrics.Enabled, "metrics", c.Metrics.Enabled, "Serve Prometheus metrics at /metrics on the http and sse transports, behind the same authentication as /mcp")
+	fs.DurationVar(&c.Metrics.PostureInterval.Duration, "metrics-posture-interval", c.Metrics.PostureInterval.Duration, "How often to collect compliance data for the posture metrics")
+}
+
+// stringList is a comma-separated flag value. Setting it replaces the
+// list from the configuration file rather than appending to it.
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*l = items
+	return nil
+}
+
+// durationMap is a flag value of comma-separated key=duration pairs
+type durationMap map[string]metav1.Duration
+
+func (m *durationMap) String() string {
+	if m == nil {
+		return ""
+	}
+	var pairs []string
+	for key, duration := range *m {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, duration.Duration))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m *durationMap) Set(value string) error {
+	durations := make(map[string]metav1.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected tool=duration, got %q", pair)
+		}
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration for %s: %w", key, err)
+		}
+		durations[key] = metav1.Duration{Duration: duration}
+	}
+	*m = durations
+	return nil
+}
+
+// int32Value is an int32 flag value
+type int32Value struct {
+	target *int32
+}
+
+func (v int32Value) String() string {
+	if v.target == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*v.target), 10)
+}
+
+func (v int32Value) Set(value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v.target = int32(parsed)
+	return nil
+}
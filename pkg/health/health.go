@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status values reported for the probe and each check
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Check is a named readiness condition
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of one check in a probe response
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Response is the JSON body of a probe
+type Response struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Handler serves a probe that runs every check concurrently, bounded by
+// timeout. It responds 200 when all checks pass and 503 otherwise, with the
+// status of each check as JSON. A handler without checks always passes.
+func Handler(timeout time.Duration, checks ...Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		response := Run(ctx, checks)
+
+		w.Header().Set("Content-Type", "application/json")
+		if response.Status != StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
+
+// Run runs the checks concurrently and collects their results in order
+func Run(ctx context.Context, checks []Check) Response {
+	response := Response{
+		Status: StatusOK,
+		Checks: make([]CheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			result := CheckResult{Name: check.Name, Status: StatusOK}
+			if err := check.Run(ctx); err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+			result.DurationMs = time.Since(start).Milliseconds()
+			response.Checks[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range response.Checks {
+		if result.Status != StatusOK {
+			response.Status = StatusFailed
+			break
+		}
+	}
+
+	return response
+}
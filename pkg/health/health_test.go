@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ok := Check{Name: "kubernetes-api", Run: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "compliance-crds", Run: func(ctx context.Context) error { return errors.New("not installed") }}
+	slow := Check{Name: "operator", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus int
+		want       Response
+	}{
+		{
+			name:       "no checks",
+			wantStatus: http.StatusOK,
+			want:       Response{Status: StatusOK, Checks: []CheckResult{}},
+		},
+		{
+			name:       "all pass",
+			checks:     []Check{ok},
+			wantStatus: http.StatusOK,
+			want:       Response{Status: StatusOK, Checks: []CheckResult{{Name: "kubernetes-api", Status: StatusOK}}},
+		},
+		{
+			name:       "one fails",
+			checks:     []Check{ok, failing},
+			wantStatus: http.StatusServiceUnavailable,
+			want: Response{Status: StatusFailed, Checks: []CheckResult{
+				{Name: "kubernetes-api", Status: StatusOK},
+				{Name: "compliance-crds", Status: StatusFailed, Error: "not installed"},
+			}},
+		},
+		{
+			name:       "timeout",
+			checks:     []Check{slow, ok},
+			wantStatus: http.StatusServiceUnavailable,
+			want: Response{Status: StatusFailed, Checks: []CheckResult{
+				{Name: "operator", Status: StatusFailed, Error: context.DeadlineExceeded.Error()},
+				{Name: "kubernetes-api", Status: StatusOK},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler(50*time.Millisecond, tt.checks...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			var got Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("body is not JSON: %v", err)
+			}
+			for i := range got.Checks {
+				got.Checks[i].DurationMs = 0
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/audit"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+)
+
+// anonymousUser identifies callers on transports without authentication
+const anonymousUser = "anonymous"
+
+// withAudit wraps a tool handler so every invocation is recorded in the
+// audit log
+func (s *MCPServer) withAudit(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+
+		event := audit.Event{
+			Time:       start.UTC(),
+			User:       anonymousUser,
+			Tool:       name,
+			Arguments:  request.GetArguments(),
+			Namespace:  s.namespace,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+
+		if user, ok := auth.UserFromContext(ctx); ok {
+			event.User = user.Name
+			event.Groups = user.Groups
+		}
+
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			event.SessionID = session.SessionID()
+		}
+
+		if namespace, ok := event.Arguments["namespace"].(string); ok && namespace != "" {
+			event.Namespace = namespace
+		}
+
+		if result != nil {
+			event.ResultBytes = resultSize(result)
+			event.IsError = result.IsError
+			if result.IsError {
+				event.Error = resultText(result)
+			}
+		}
+		if err != nil {
+			event.IsError = true
+			event.Error = err.Error()
+		}
+
+		if auditErr := s.audit.Record(event); auditErr != nil {
+			log.Printf("Failed to record audit event for %s: %v", name, auditErr)
+		}
+
+		return result, err
+	}
+}
+
+// resultSize returns the size in bytes of a tool result's content
+func resultSize(result *mcp.CallToolResult) int {
+	size := 0
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			size += len(c.Text)
+		case mcp.EmbeddedResource:
+			if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+				size += len(text.Text)
+			}
+		}
+	}
+	return size
+}
+
+// resultText returns the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
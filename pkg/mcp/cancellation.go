@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// requestIDMetaKey is the request _meta field used to carry the JSON-RPC
+// request ID from the before-call hook to the tool handler
+const requestIDMetaKey = "compliance-mcp/requestId"
+
+// callTracker tracks in-flight tool calls so that notifications/cancelled
+// from the client stops the matching call
+type callTracker struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newCallTracker() *callTracker {
+	return &callTracker{cancels: make(map[string]context.CancelFunc)}
+}
+
+// beforeCallTool records the JSON-RPC request ID on the request so the
+// handler can register itself for cancellation
+func (t *callTracker) beforeCallTool(ctx context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMetaKey] = id
+}
+
+// track returns a context that is cancelled when the client cancels the
+// request, and a function that must be called when the call finishes
+func (t *callTracker) track(ctx context.Context, request mcp.CallToolRequest) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	if request.Params.Meta == nil {
+		return ctx, cancel
+	}
+	id, ok := request.Params.Meta.AdditionalFields[requestIDMetaKey]
+	if !ok {
+		return ctx, cancel
+	}
+
+	key := callKey(ctx, id)
+	t.mu.Lock()
+	t.cancels[key] = cancel
+	t.mu.Unlock()
+
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.cancels, key)
+		t.mu.Unlock()
+		cancel()
+	}
+}
+
+// handleCancelled cancels the in-flight call named by a
+// notifications/cancelled message
+func (t *callTracker) handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	cancel, ok := t.cancels[callKey(ctx, id)]
+	t.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// callKey identifies a request within the session it was sent on
+func callKey(ctx context.Context, id any) string {
+	sessionID := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	return sessionID + "/" + mcp.NewRequestId(id).String()
+}
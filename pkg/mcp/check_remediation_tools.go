@@ -9,21 +9,28 @@ import (
 
 // CheckResultsArgs holds arguments for compliance_check_results tool
 type CheckResultsArgs struct {
+	Cluster        string  `json:"cluster,omitempty"`
 	ScanName       string  `json:"scan_name"`
 	Namespace      string  `json:"namespace"`
 	StatusFilter   *string `json:"status_filter,omitempty"`
 	SeverityFilter *string `json:"severity_filter,omitempty"`
+	Cursor         string  `json:"cursor,omitempty"`
+	Item           string  `json:"item,omitempty"`
 }
 
 // RemediationsArgs holds arguments for compliance_remediations tool
 type RemediationsArgs struct {
-	ScanName    string  `json:"scan_name,omitempty"`
-	Namespace   string  `json:"namespace"`
-	AppliedOnly bool    `json:"applied_only"`
+	Cluster     string `json:"cluster,omitempty"`
+	ScanName    string `json:"scan_name,omitempty"`
+	Namespace   string `json:"namespace"`
+	AppliedOnly bool   `json:"applied_only"`
+	Cursor      string `json:"cursor,omitempty"`
+	Item        string `json:"item,omitempty"`
 }
 
-// ComplianceCheckResults lists check results for a scan
-func ComplianceCheckResults(ctx context.Context, client *compliance.ComplianceClient, args CheckResultsArgs) (string, error) {
+// ComplianceCheckResults lists check results for a scan, keeping the output
+// within budget bytes
+func ComplianceCheckResults(ctx context.Context, client *compliance.ComplianceClient, args CheckResultsArgs, budget int) (string, error) {
 	statusFilter := ""
 	if args.StatusFilter != nil {
 		statusFilter = *args.StatusFilter
@@ -46,11 +53,27 @@ func ComplianceCheckResults(ctx context.Context, client *compliance.ComplianceCl
 		checkResults = filtered
 	}
 
-	return FormatCheckResults(checkResults), nil
+	// Return the full detail of a single named result if requested
+	if args.Item != "" {
+		for _, result := range checkResults {
+			if result.Name == args.Item {
+				return truncateText(FormatCheckResultDetail(result), budget), nil
+			}
+		}
+		return "", fmt.Errorf("check result '%s' not found", args.Item)
+	}
+
+	items := make([]string, len(checkResults))
+	for i, result := range checkResults {
+		items[i] = formatCheckResultItem(i, result)
+	}
+
+	return paginateItems(FormatCheckResultsSummary(checkResults), items, args.Cursor, budget)
 }
 
-// ComplianceRemediations gets available remediations
-func ComplianceRemediations(ctx context.Context, client *compliance.ComplianceClient, args RemediationsArgs) (string, error) {
+// ComplianceRemediations gets available remediations, keeping the output
+// within budget bytes
+func ComplianceRemediations(ctx context.Context, client *compliance.ComplianceClient, args RemediationsArgs, budget int) (string, error) {
 	// Get remediations
 	remediations, err := client.GetComplianceRemediations(ctx, args.ScanName)
 	if err != nil {
@@ -68,5 +91,20 @@ func ComplianceRemediations(ctx context.Context, client *compliance.ComplianceCl
 		remediations = filtered
 	}
 
-	return FormatRemediations(remediations), nil
+	// Return a single named remediation if requested
+	if args.Item != "" {
+		for i, rem := range remediations {
+			if rem.Name == args.Item {
+				return formatRemediationItem(i, rem), nil
+			}
+		}
+		return "", fmt.Errorf("remediation '%s' not found", args.Item)
+	}
+
+	items := make([]string, len(remediations))
+	for i, rem := range remediations {
+		items[i] = formatRemediationItem(i, rem)
+	}
+
+	return paginateItems(FormatRemediationsSummary(remediations), items, args.Cursor, budget)
 }
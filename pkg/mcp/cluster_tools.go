@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// clusterCheckTimeout bounds how long compliance_clusters waits for each
+// cluster, so one unreachable cluster doesn't hold up the report
+const clusterCheckTimeout = 10 * time.Second
+
+// ClustersArgs holds arguments for compliance_clusters tool
+type ClustersArgs struct {
+	Namespace string `json:"namespace"`
+}
+
+// ClusterTarget is a configured cluster and the client to query it with
+type ClusterTarget struct {
+	Name    string
+	Default bool
+	Client  *compliance.ComplianceClient
+}
+
+// clusterStatus is the outcome of checking one cluster
+type clusterStatus struct {
+	reachableErr error
+	operatorErr  error
+	version      string
+}
+
+// ComplianceClusters lists the configured clusters with whether their API
+// server is reachable and which Compliance Operator version they run
+func ComplianceClusters(ctx context.Context, targets []ClusterTarget) (string, error) {
+	statuses := make([]clusterStatus, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, client *compliance.ComplianceClient) {
+			defer wg.Done()
+			statuses[i] = checkCluster(ctx, client)
+		}(i, target.Client)
+	}
+	wg.Wait()
+
+	var output strings.Builder
+	output.WriteString("# Configured Clusters\n\n")
+	output.WriteString("| Cluster | API Server | Reachable | Operator Version |\n")
+	output.WriteString("|---------|------------|-----------|------------------|\n")
+
+	reachable := 0
+	var problems []string
+	for i, target := range targets {
+		status := statuses[i]
+
+		name := target.Name
+		if target.Default {
+			name += " (default)"
+		}
+
+		reachableText, version := "✅ Yes", status.version
+		switch {
+		case status.reachableErr != nil:
+			reachableText, version = "❌ No", "-"
+			problems = append(problems, fmt.Sprintf("**%s**: %v", target.Name, status.reachableErr))
+		case status.operatorErr != nil:
+			version = "not installed"
+			problems = append(problems, fmt.Sprintf("**%s**: %v", target.Name, status.operatorErr))
+		}
+		if status.reachableErr == nil {
+			reachable++
+		}
+		if version == "" {
+			version = "unknown"
+		}
+
+		output.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, target.Client.Host(), reachableText, version))
+	}
+
+	output.WriteString(fmt.Sprintf("\n**Reachable:** %d of %d clusters\n", reachable, len(targets)))
+
+	if len(problems) > 0 {
+		output.WriteString("\n## Problems\n\n")
+		for _, problem := range problems {
+			output.WriteString(fmt.Sprintf("- %s\n", problem))
+		}
+	}
+
+	output.WriteString("\nPass `cluster` to any tool to query a cluster other than the default.\n")
+
+	return output.String(), nil
+}
+
+// checkCluster checks one cluster's API server, Compliance Operator API and
+// operator version within clusterCheckTimeout
+func checkCluster(ctx context.Context, client *compliance.ComplianceClient) clusterStatus {
+	ctx, cancel := context.WithTimeout(ctx, clusterCheckTimeout)
+	defer cancel()
+
+	var status clusterStatus
+	if status.reachableErr = client.CheckAPIServer(ctx); status.reachableErr != nil {
+		return status
+	}
+	if status.operatorErr = client.CheckComplianceAPI(ctx); status.operatorErr != nil {
+		return status
+	}
+
+	// The version is informational, so a failure to read it only leaves it
+	// unknown
+	status.version, _ = client.GetOperatorVersion(ctx)
+	return status
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// clusterClients holds the clients for one configured cluster
+type clusterClients struct {
+	client *compliance.ComplianceClient
+	// impersonation is set when per-caller clients are enabled
+	impersonation *compliance.ImpersonatingClients
+}
+
+// setupClusters creates clients for the configured clusters, or for the
+// cluster GetKubeConfig finds when none are configured
+func (s *MCPServer) setupClusters() error {
+	clusters := s.clusterConfigs
+	if len(clusters) == 0 {
+		config, err := compliance.GetKubeConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get kubeconfig: %w", err)
+		}
+		clusters = []compliance.Cluster{{Name: compliance.DefaultClusterName, Config: config}}
+	}
+
+	s.clusters = make(map[string]*clusterClients, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster without a name")
+		}
+		if _, ok := s.clusters[cluster.Name]; ok {
+			return fmt.Errorf("cluster %q is configured more than once", cluster.Name)
+		}
+
+		client, err := compliance.NewComplianceClientForConfig(cluster.Config, s.namespace)
+		if err != nil {
+			return fmt.Errorf("failed to create compliance client for cluster %s: %w", cluster.Name, err)
+		}
+
+		clients := &clusterClients{client: client}
+		if s.impersonate {
+			clients.impersonation = compliance.NewImpersonatingClients(cluster.Config, s.namespace)
+		}
+
+		s.clusters[cluster.Name] = clients
+		s.clusterNames = append(s.clusterNames, cluster.Name)
+	}
+
+	if s.defaultCluster == "" {
+		s.defaultCluster = s.clusterNames[0]
+	}
+	defaultClients, ok := s.clusters[s.defaultCluster]
+	if !ok {
+		return fmt.Errorf("default cluster %q is not configured (clusters: %s)", s.defaultCluster, strings.Join(s.clusterNames, ", "))
+	}
+	s.client = defaultClients.client
+
+	return nil
+}
+
+// setClusterLogger sends API call events from every cluster's clients to
+// logger, and to observer if set
+func (s *MCPServer) setClusterLogger(logger compliance.Logger, observer compliance.APIObserver) {
+	for _, clients := range s.clusters {
+		clients.client.SetLogger(logger)
+		if clients.impersonation != nil {
+			clients.impersonation.SetLogger(logger)
+		}
+		if observer != nil {
+			clients.client.SetAPIObserver(observer)
+			if clients.impersonation != nil {
+				clients.impersonation.SetAPIObserver(observer)
+			}
+		}
+	}
+}
+
+// clientFor returns the compliance client to use for a request against a
+// cluster and namespace. An empty cluster means the default cluster. With
+// impersonation enabled this is a client acting as the authenticated
+// caller, so the caller's own RBAC applies; otherwise it is the server's
+// client.
+func (s *MCPServer) clientFor(ctx context.Context, cluster, namespace string) (*compliance.ComplianceClient, error) {
+	cluster = s.clusterName(cluster)
+	clients, ok := s.clusters[cluster]
+	if !ok {
+		return nil, fmt.Errorf("unknown cluster %q (configured: %s)", cluster, strings.Join(s.clusterNames, ", "))
+	}
+
+	if !s.namespaceAllowed(namespace) {
+		return nil, fmt.Errorf("namespace %q is not allowed (allowed: %s)", namespace, strings.Join(append([]string{s.namespace}, s.allowedNamespaces...), ", "))
+	}
+
+	client := clients.client
+	if clients.impersonation != nil {
+		user, ok := auth.UserFromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("impersonation is enabled but the request is not authenticated")
+		}
+
+		var err error
+		client, err = clients.impersonation.ForUser(user.Name, user.UID, user.Groups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return client.InNamespace(namespace), nil
+}
+
+// clusterName resolves the cluster a tool call names, which is the default
+// cluster when it names none
+func (s *MCPServer) clusterName(cluster string) string {
+	if cluster == "" {
+		return s.defaultCluster
+	}
+	return cluster
+}
+
+// clusterTargets returns a client for every configured cluster in the
+// given namespace, in configuration order
+func (s *MCPServer) clusterTargets(ctx context.Context, namespace string) ([]ClusterTarget, error) {
+	targets := make([]ClusterTarget, 0, len(s.clusterNames))
+	for _, name := range s.clusterNames {
+		client, err := s.clientFor(ctx, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, ClusterTarget{
+			Name:    name,
+			Default: name == s.defaultCluster,
+			Client:  client,
+		})
+	}
+	return targets, nil
+}
+
+// clusterProperty is the input schema of the cluster argument every tool
+// accepts
+func (s *MCPServer) clusterProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Cluster to query (see compliance_clusters)",
+		"enum":        s.clusterNames,
+		"default":     s.defaultCluster,
+	}
+}
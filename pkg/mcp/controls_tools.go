@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// ControlsArgs holds arguments for compliance_controls tool
+type ControlsArgs struct {
+	Cluster      string  `json:"cluster,omitempty"`
+	Framework    string  `json:"framework,omitempty"`
+	Control      string  `json:"control,omitempty"`
+	SuiteName    string  `json:"suite_name,omitempty"`
+	ScanName     string  `json:"scan_name,omitempty"`
+	StatusFilter *string `json:"status_filter,omitempty"`
+	Namespace    string  `json:"namespace"`
+	Cursor       string  `json:"cursor,omitempty"`
+}
+
+// ComplianceControls rolls check results up to the controls of a framework,
+// keeping the output within budget bytes. Without a suite or a scan every
+// scan in the namespace is assessed.
+func ComplianceControls(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ControlsArgs, budget int) (string, error) {
+	results, scope, err := controlResults(ctx, client, args)
+	if err != nil {
+		return "", err
+	}
+
+	// Without access to rules the fallback mapping still answers for the
+	// common rules
+	rules, rulesErr := client.GetRules(ctx)
+	mapping := compliance.NewControlMapping(rules)
+	frameworks := mapping.Frameworks(results)
+	framework := resolveFramework(args.Framework, frameworks)
+
+	rollup := compliance.NewControlRollup(results, mapping, framework)
+
+	var status compliance.ComplianceCheckStatus
+	if args.StatusFilter != nil {
+		status = compliance.ComplianceCheckStatus(strings.ToUpper(*args.StatusFilter))
+	}
+	controls := rollup.Filter(args.Control, status)
+
+	summary := formatControlsSummary(rollup, scope, cluster, frameworks, args.Control, status, len(controls), rulesErr)
+
+	items := make([]string, len(controls))
+	for i, control := range controls {
+		items[i] = formatControlItem(control)
+	}
+
+	return paginateItems(summary, items, args.Cursor, budget)
+}
+
+// controlResults gathers the check results to roll up and describes their
+// scope: a scan, the scans of a suite, or every scan in the namespace
+func controlResults(ctx context.Context, client *compliance.ComplianceClient, args ControlsArgs) ([]compliance.ComplianceCheckResult, string, error) {
+	if args.ScanName != "" {
+		results, err := client.GetComplianceCheckResults(ctx, args.ScanName, "")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get check results: %w", err)
+		}
+		return results, "scan " + args.ScanName, nil
+	}
+
+	scope := "all scans"
+	if args.SuiteName != "" {
+		if _, err := client.GetComplianceSuite(ctx, args.SuiteName); err != nil {
+			return nil, "", err
+		}
+		scope = "suite " + args.SuiteName
+	}
+
+	scans, err := client.GetComplianceScans(ctx, args.SuiteName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var results []compliance.ComplianceCheckResult
+	for _, scan := range scans {
+		scanResults, err := client.GetComplianceCheckResults(ctx, scan.Name, "")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get check results of scan %s: %w", scan.Name, err)
+		}
+		results = append(results, scanResults...)
+	}
+	return results, scope, nil
+}
+
+// resolveFramework matches the requested framework case-insensitively
+// against the frameworks the rules map to and the known ones, defaulting to
+// NIST 800-53
+func resolveFramework(requested string, available []string) string {
+	if requested == "" {
+		return compliance.NISTFramework
+	}
+	for _, frameworks := range [][]string{available, compliance.ControlFrameworks} {
+		for _, framework := range frameworks {
+			if strings.EqualFold(framework, requested) {
+				return framework
+			}
+		}
+	}
+	return requested
+}
+
+// formatControlsSummary formats the header of a control listing
+func formatControlsSummary(rollup *compliance.ControlRollup, scope, cluster string, frameworks []string, control string, status compliance.ComplianceCheckStatus, matched int, rulesErr error) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("# %s Controls: %s (cluster %s)\n\n", rollup.Framework, scope, cluster))
+
+	if rulesErr != nil {
+		output.WriteString(fmt.Sprintf("⚠️ Rules could not be listed (%v); controls come from the embedded fallback mapping only.\n\n", rulesErr))
+	}
+
+	if rollup.Rules == 0 {
+		output.WriteString("No check results found.\n")
+		return output.String()
+	}
+
+	byStatus := make(map[compliance.ComplianceCheckStatus]int)
+	for _, c := range rollup.Controls {
+		byStatus[c.Status]++
+	}
+	other := len(rollup.Controls) - byStatus[compliance.CheckFail] - byStatus[compliance.CheckManual] - byStatus[compliance.CheckPass]
+
+	output.WriteString(fmt.Sprintf("**Controls:** %d (Fail: %d, Manual: %d, Pass: %d, Other: %d)\n",
+		len(rollup.Controls), byStatus[compliance.CheckFail], byStatus[compliance.CheckManual], byStatus[compliance.CheckPass], other))
+	output.WriteString(fmt.Sprintf("**Rules:** %d of %d assessed rules map to %s controls", rollup.Mapped, rollup.Rules, rollup.Framework))
+	if rollup.Fallback > 0 {
+		output.WriteString(fmt.Sprintf(" (%d through the embedded fallback mapping)", rollup.Fallback))
+	}
+	output.WriteString("\n")
+
+	var others []string
+	for _, framework := range frameworks {
+		if framework != rollup.Framework {
+			others = append(others, framework)
+		}
+	}
+	if len(others) > 0 {
+		output.WriteString(fmt.Sprintf("**Other Frameworks:** %s\n", strings.Join(others, ", ")))
+	}
+
+	if control != "" || status != "" {
+		var filters []string
+		if control != "" {
+			filters = append(filters, "control "+control)
+		}
+		if status != "" {
+			filters = append(filters, "status "+string(status))
+		}
+		output.WriteString(fmt.Sprintf("**Filter:** %s (%d controls match)\n", strings.Join(filters, ", "), matched))
+	}
+	output.WriteString("\n")
+
+	if rollup.Mapped == 0 {
+		output.WriteString(fmt.Sprintf("No assessed rule maps to a %s control.\n", rollup.Framework))
+	} else if matched == 0 {
+		output.WriteString("No control matches the filter.\n")
+	}
+
+	return output.String()
+}
+
+// formatControlItem formats one control with its contributing rules
+func formatControlItem(control compliance.ControlStatus) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("## %s %s %s\n\n", control.Control, getStatusIcon(control.Status), control.Status))
+	output.WriteString(fmt.Sprintf("**Rules:** Pass: %d, Fail: %d, Manual: %d, Other: %d\n\n", control.Pass, control.Fail, control.Manual, control.Other))
+
+	for _, rule := range control.Rules {
+		line := fmt.Sprintf("- %s %s %s", getStatusIcon(rule.Status), rule.Name, rule.Status)
+		if badge := getSeverityBadge(rule.Severity); badge != "" {
+			line += " " + badge
+		}
+		if rule.Title != "" {
+			line += ": " + rule.Title
+		}
+		line += fmt.Sprintf(" (checks: %s)", strings.Join(rule.Checks, ", "))
+		if rule.Source == compliance.ControlSourceFallback {
+			line += " [fallback mapping]"
+		}
+		output.WriteString(line + "\n")
+	}
+	output.WriteString("\n")
+
+	return output.String()
+}
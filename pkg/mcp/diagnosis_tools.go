@@ -9,6 +9,7 @@ import (
 
 // DiagnoseArgs holds arguments for compliance_diagnose tool
 type DiagnoseArgs struct {
+	Cluster   string  `json:"cluster,omitempty"`
 	Namespace string  `json:"namespace"`
 	SuiteName *string `json:"suite_name,omitempty"`
 }
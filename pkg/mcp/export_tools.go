@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// Export formats rendered by compliance_export
+const (
+	ExportFormatOSCAL = "oscal"
+	ExportFormatXCCDF = "xccdf"
+	ExportFormatSARIF = "sarif"
+	ExportFormatCSV   = "csv"
+	ExportFormatTSV   = "tsv"
+	ExportFormatJUnit = "junit"
+)
+
+// exportFormats lists the formats compliance_export renders
+var exportFormats = []string{ExportFormatOSCAL, ExportFormatXCCDF, ExportFormatSARIF, ExportFormatCSV, ExportFormatTSV, ExportFormatJUnit}
+
+// ExportArgs holds arguments for compliance_export tool. Columns and the
+// filters apply to the csv and tsv formats.
+type ExportArgs struct {
+	Cluster        string   `json:"cluster,omitempty"`
+	Format         string   `json:"format"`
+	SuiteName      string   `json:"suite_name,omitempty"`
+	ScanName       string   `json:"scan_name,omitempty"`
+	Namespace      string   `json:"namespace"`
+	Columns        []string `json:"columns,omitempty"`
+	StatusFilter   *string  `json:"status_filter,omitempty"`
+	SeverityFilter *string  `json:"severity_filter,omitempty"`
+}
+
+// XCCDFExportArgs holds arguments for compliance_xccdf_export tool
+type XCCDFExportArgs struct {
+	Cluster   string `json:"cluster,omitempty"`
+	ScanName  string `json:"scan_name"`
+	Namespace string `json:"namespace"`
+}
+
+// ComplianceXCCDFExport renders a scan's check results as an XCCDF 1.2
+// TestResult document. It returns a short summary and the document as an
+// embedded resource.
+func ComplianceXCCDFExport(ctx context.Context, client *compliance.ComplianceClient, cluster string, args XCCDFExportArgs) (string, mcp.TextResourceContents, error) {
+	if args.ScanName == "" {
+		return "", mcp.TextResourceContents{}, fmt.Errorf("scan_name is required")
+	}
+
+	scan, err := client.GetComplianceScan(ctx, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, fmt.Errorf("failed to get scan: %w", err)
+	}
+
+	results, err := client.GetComplianceCheckResults(ctx, args.ScanName, "")
+	if err != nil {
+		return "", mcp.TextResourceContents{}, fmt.Errorf("failed to get check results: %w", err)
+	}
+
+	doc := compliance.NewXCCDFTestResult(*scan, results, client.Host(), time.Now())
+	data, err := doc.Marshal()
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	counts := compliance.GetCheckCounts(results)
+	summary := fmt.Sprintf("XCCDF 1.2 TestResult for scan %s in cluster %s: %d rule results (%d pass, %d fail, %d manual, %d error), score %s.",
+		scan.Name, cluster, counts.Total, counts.Pass, counts.Fail, counts.Manual, counts.Error, doc.Score.Value)
+
+	resource := mcp.TextResourceContents{
+		URI:      compliance.ExportURI(cluster, client.Namespace(), "scans", scan.Name, "xccdf.xml"),
+		MIMEType: compliance.XCCDFMediaType,
+		Text:     string(data),
+	}
+	return summary, resource, nil
+}
+
+// ComplianceExport renders a suite's or a scan's results in the requested
+// format. It returns a short summary and the document as an embedded
+// resource.
+func ComplianceExport(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ExportArgs) (string, mcp.TextResourceContents, error) {
+	switch args.Format {
+	case ExportFormatOSCAL:
+		return exportOSCAL(ctx, client, cluster, args)
+	case ExportFormatXCCDF:
+		if args.ScanName == "" {
+			return "", mcp.TextResourceContents{}, fmt.Errorf("the xccdf format exports one scan; scan_name is required")
+		}
+		return ComplianceXCCDFExport(ctx, client, cluster, XCCDFExportArgs{
+			Cluster:   args.Cluster,
+			ScanName:  args.ScanName,
+			Namespace: args.Namespace,
+		})
+	case ExportFormatSARIF:
+		return exportSARIF(ctx, client, cluster, args)
+	case ExportFormatCSV, ExportFormatTSV:
+		return exportTable(ctx, client, cluster, args)
+	case ExportFormatJUnit:
+		return exportJUnit(ctx, client, cluster, args)
+	case "":
+		return "", mcp.TextResourceContents{}, fmt.Errorf("format is required (%s)", strings.Join(exportFormats, ", "))
+	default:
+		return "", mcp.TextResourceContents{}, fmt.Errorf("unknown format %q (supported: %s)", args.Format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// exportOSCAL renders an OSCAL assessment-results document
+func exportOSCAL(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ExportArgs) (string, mcp.TextResourceContents, error) {
+	data, err := client.CollectExport(ctx, cluster, args.SuiteName, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	rules, err := client.GetRules(ctx)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+	// Rules without control annotations take their controls from the
+	// embedded fallback mapping
+	rules = compliance.NewControlMapping(rules).Rules(data.Results())
+
+	// Checks of scans whose nodes cannot be listed are attributed to the
+	// cluster, which still makes a valid document
+	nodes := make(map[string][]string)
+	var nodeErr error
+	for _, export := range data.Scans {
+		if export.Scan.Spec.ScanType == compliance.ScanTypePlatform {
+			continue
+		}
+		scanNodes, err := client.GetScanNodes(ctx, export.Scan)
+		if err != nil {
+			nodeErr = err
+			continue
+		}
+		nodes[export.Scan.Name] = scanNodes
+	}
+
+	doc := compliance.NewOSCALAssessmentResults(data, rules, nodes, time.Now())
+	out, err := doc.Marshal()
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	result := doc.AssessmentResults.Results[0]
+	controls := 0
+	for _, selection := range result.ReviewedControls.ControlSelections {
+		controls += len(selection.IncludeControls)
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("OSCAL %s assessment-results for %s in cluster %s: %d scans, %d observations, %d findings, %d NIST 800-53 controls reviewed.",
+		compliance.OSCALVersion, data.Title(), cluster, len(data.Scans), len(result.Observations), len(result.Findings), controls))
+	if controls == 0 {
+		summary.WriteString(" No rule maps to NIST 800-53 controls, so no control is marked as reviewed.")
+	}
+	if nodeErr != nil {
+		summary.WriteString(fmt.Sprintf(" Nodes could not be listed (%v); node checks are attributed to the cluster.", nodeErr))
+	}
+
+	resource := mcp.TextResourceContents{
+		URI:      data.URI("oscal-assessment-results.json"),
+		MIMEType: compliance.OSCALMediaType,
+		Text:     string(out),
+	}
+	return summary.String(), resource, nil
+}
+
+// exportSARIF renders a SARIF log of the failed and errored checks
+func exportSARIF(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ExportArgs) (string, mcp.TextResourceContents, error) {
+	data, err := client.CollectExport(ctx, cluster, args.SuiteName, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	log := compliance.NewSARIFLog(data)
+	out, err := log.Marshal()
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	counts := compliance.GetCheckCounts(data.Results())
+	run := log.Runs[0]
+	summary := fmt.Sprintf("SARIF %s log for %s in cluster %s: %d rules, %d results (%d failed, %d errored checks).",
+		compliance.SARIFVersion, data.Title(), cluster, len(run.Tool.Driver.Rules), len(run.Results), counts.Fail, counts.Error)
+
+	resource := mcp.TextResourceContents{
+		URI:      data.URI("results.sarif"),
+		MIMEType: compliance.SARIFMediaType,
+		Text:     string(out),
+	}
+	return summary, resource, nil
+}
+
+// exportTable renders one CSV or TSV row per check result
+func exportTable(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ExportArgs) (string, mcp.TextResourceContents, error) {
+	data, err := client.CollectExport(ctx, cluster, args.SuiteName, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	opts := compliance.TableOptions{
+		Columns: args.Columns,
+		TSV:     args.Format == ExportFormatTSV,
+	}
+	if args.StatusFilter != nil {
+		opts.Status = *args.StatusFilter
+	}
+	if args.SeverityFilter != nil {
+		opts.Severity = *args.SeverityFilter
+	}
+
+	out, rows, err := compliance.ResultsTable(data, opts)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	mimeType := compliance.CSVMediaType
+	if opts.TSV {
+		mimeType = compliance.TSVMediaType
+	}
+
+	summary := fmt.Sprintf("%s of %s in cluster %s: %d check results from %d scans.",
+		strings.ToUpper(args.Format), data.Title(), cluster, rows, len(data.Scans))
+
+	resource := mcp.TextResourceContents{
+		URI:      data.URI("check-results." + args.Format),
+		MIMEType: mimeType,
+		Text:     string(out),
+	}
+	return summary, resource, nil
+}
+
+// exportJUnit renders a JUnit XML report with a testsuite per scan
+func exportJUnit(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ExportArgs) (string, mcp.TextResourceContents, error) {
+	data, err := client.CollectExport(ctx, cluster, args.SuiteName, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	report := compliance.NewJUnitReport(data)
+	out, err := report.Marshal()
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	summary := fmt.Sprintf("JUnit report for %s in cluster %s: %d testsuites, %d tests, %d failures (%d high severity), %d errors, %d skipped.",
+		data.Title(), cluster, len(report.Suites), report.Tests, report.Failures, compliance.CountFailures(data, "high", false), report.Errors, report.Skipped)
+
+	resource := mcp.TextResourceContents{
+		URI:      data.URI("junit.xml"),
+		MIMEType: compliance.JUnitMediaType,
+		Text:     string(out),
+	}
+	return summary, resource, nil
+}
+
+// createResourceResult returns a summary and an embedded document
+func createResourceResult(summary string, resource mcp.TextResourceContents) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: summary,
+			},
+			mcp.NewEmbeddedResource(resource),
+		},
+		IsError: false,
+	}
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// fleetConcurrency caps how many clusters compliance_fleet_summary collects
+// from at once
+const fleetConcurrency = 8
+
+// defaultTopRules is how many failing rules compliance_fleet_summary lists
+// when the caller doesn't say
+const defaultTopRules = 10
+
+// FleetSummaryArgs holds arguments for compliance_fleet_summary tool
+type FleetSummaryArgs struct {
+	Namespace      string  `json:"namespace"`
+	Profile        *string `json:"profile,omitempty"`
+	SeverityFilter *string `json:"severity_filter,omitempty"`
+	TopRules       int     `json:"top_rules"`
+}
+
+// ComplianceFleetSummary formats a fleet rollup as a cluster × profile
+// compliance matrix, the most widespread failing rules and the clusters
+// whose operator is unhealthy
+func ComplianceFleetSummary(rollup *compliance.FleetRollup, args FleetSummaryArgs) string {
+	var output strings.Builder
+
+	output.WriteString("# Fleet Compliance Summary\n\n")
+
+	profiles := rollup.Profiles
+	if args.Profile != nil && *args.Profile != "" {
+		profiles = nil
+		for _, profile := range rollup.Profiles {
+			if profileMatches(profile, *args.Profile) {
+				profiles = append(profiles, profile)
+			}
+		}
+		output.WriteString(fmt.Sprintf("**Profile filter:** %s\n", *args.Profile))
+	}
+	if args.SeverityFilter != nil && *args.SeverityFilter != "" {
+		output.WriteString(fmt.Sprintf("**Severity filter:** %s\n", *args.SeverityFilter))
+	}
+
+	collected := 0
+	for _, cluster := range rollup.Clusters {
+		if cluster.Err == nil {
+			collected++
+		}
+	}
+	output.WriteString(fmt.Sprintf("**Clusters:** %d (%d collected)\n", len(rollup.Clusters), collected))
+	output.WriteString(fmt.Sprintf("**Collected:** %s\n\n", rollup.Timestamp.Format("2006-01-02 15:04:05")))
+
+	// Matrix of compliance percentage per cluster and profile
+	output.WriteString("## Compliance by Cluster and Profile\n\n")
+	if len(profiles) == 0 {
+		output.WriteString("No matching scan results found in any cluster.\n\n")
+	} else {
+		output.WriteString("| Cluster | " + strings.Join(profiles, " | ") + " |\n")
+		output.WriteString("|---------|" + strings.Repeat("---|", len(profiles)) + "\n")
+		for _, cluster := range rollup.Clusters {
+			cells := make([]string, len(profiles))
+			for i, profile := range profiles {
+				counts, ok := cluster.Profiles[profile]
+				switch {
+				case cluster.Err != nil:
+					cells[i] = "n/a"
+				case !ok:
+					cells[i] = "-"
+				default:
+					cells[i] = fmt.Sprintf("%.1f%% (%d fail)", compliance.CalculateCompliancePercentage(counts), counts.Fail)
+				}
+			}
+			output.WriteString(fmt.Sprintf("| %s | %s |\n", cluster.Name, strings.Join(cells, " | ")))
+		}
+		output.WriteString("\nPercentages count PASS against PASS + FAIL; `-` means the profile isn't scanned there and `n/a` that the cluster couldn't be collected.\n\n")
+	}
+
+	// Failing rules across the fleet. With a profile filter only failures
+	// in matching profiles count, so a rule lists just the clusters where
+	// one of those profiles failed it.
+	var rules []compliance.FailingRule
+	for _, rule := range rollup.FailingRules {
+		if args.SeverityFilter != nil && *args.SeverityFilter != "" && !strings.EqualFold(rule.Severity, *args.SeverityFilter) {
+			continue
+		}
+		if args.Profile != nil && *args.Profile != "" {
+			var ok bool
+			if rule, ok = rule.ForProfiles(func(profile string) bool { return profileMatches(profile, *args.Profile) }); !ok {
+				continue
+			}
+		}
+		rules = append(rules, rule)
+	}
+	compliance.SortFailingRules(rules)
+
+	clusterFailures := make(map[string]int)
+	for _, rule := range rules {
+		for _, cluster := range rule.Clusters {
+			clusterFailures[cluster]++
+		}
+	}
+
+	top := args.TopRules
+	if top <= 0 {
+		top = defaultTopRules
+	}
+	output.WriteString(fmt.Sprintf("## Top Failing Rules (%d of %d)\n\n", min(top, len(rules)), len(rules)))
+	if len(rules) == 0 {
+		output.WriteString("No failing rules match. ✅\n\n")
+	} else {
+		for i, rule := range rules {
+			if i == top {
+				break
+			}
+			severity := getSeverityBadge(rule.Severity)
+			if severity == "" {
+				severity = "UNKNOWN"
+			}
+			output.WriteString(fmt.Sprintf("%d. **%s** %s: fails in %d of %d clusters (%s)\n",
+				i+1, compliance.RuleName(rule.ID), severity, len(rule.Clusters), len(rollup.Clusters), strings.Join(rule.Clusters, ", ")))
+			if rule.Description != "" {
+				output.WriteString(fmt.Sprintf("   %s\n", firstLine(rule.Description)))
+			}
+		}
+		output.WriteString("\n")
+
+		output.WriteString("### Failing Rules per Cluster\n\n")
+		for _, cluster := range rollup.Clusters {
+			if count := clusterFailures[cluster.Name]; count > 0 {
+				output.WriteString(fmt.Sprintf("- **%s**: %d rules\n", cluster.Name, count))
+			}
+		}
+		output.WriteString("\n")
+	}
+
+	// Operator health and collection problems. A partly collected cluster
+	// may have stopped before its operator status was read, which says
+	// nothing about its health.
+	var unhealthy, unknown, problems []string
+	for _, cluster := range rollup.Clusters {
+		switch {
+		case cluster.Err != nil:
+			problems = append(problems, fmt.Sprintf("**%s**: %v", cluster.Name, cluster.Err))
+		case cluster.Partial != nil && len(cluster.OperatorStatus.OperatorPods) == 0 && len(cluster.OperatorStatus.Issues) == 0:
+			unknown = append(unknown, fmt.Sprintf("**%s**: unknown (not collected)", cluster.Name))
+		case !cluster.OperatorStatus.IsHealthy:
+			issues := strings.Join(cluster.OperatorStatus.Issues, "; ")
+			if issues == "" {
+				issues = "no running operator pods"
+			}
+			unhealthy = append(unhealthy, fmt.Sprintf("**%s**: %s", cluster.Name, issues))
+		}
+		if cluster.Partial != nil {
+			problems = append(problems, fmt.Sprintf("**%s**: incomplete because %s (skipped: %s)",
+				cluster.Name, cluster.Partial.Reason, strings.Join(cluster.Partial.Skipped, ", ")))
+		}
+	}
+
+	output.WriteString("## Operator Health\n\n")
+	switch {
+	case collected == 0:
+		output.WriteString("No cluster could be collected, see below.\n")
+	case len(unhealthy) == 0 && len(unknown) == 0:
+		output.WriteString("The operator is healthy in every collected cluster. ✅\n")
+	default:
+		for _, line := range unhealthy {
+			output.WriteString(fmt.Sprintf("- ❌ %s\n", line))
+		}
+		for _, line := range unknown {
+			output.WriteString(fmt.Sprintf("- ⚠️ %s\n", line))
+		}
+	}
+
+	if len(problems) > 0 {
+		output.WriteString("\n## Collection Problems\n\n")
+		for _, line := range problems {
+			output.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+	}
+
+	return output.String()
+}
+
+// fleetMembers creates a collector for every target
+func (s *MCPServer) fleetMembers(targets []ClusterTarget) []compliance.FleetMember {
+	members := make([]compliance.FleetMember, 0, len(targets))
+	for _, target := range targets {
+		members = append(members, compliance.FleetMember{
+			Name:      target.Name,
+			Collector: s.newCollector(target.Client),
+		})
+	}
+	return members
+}
+
+// collectFleet rolls up every configured cluster in a namespace
+func (s *MCPServer) collectFleet(ctx context.Context, namespace string) (*compliance.FleetRollup, error) {
+	targets, err := s.clusterTargets(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return compliance.CollectFleet(ctx, s.fleetMembers(targets), fleetConcurrency), nil
+}
+
+// profileMatches reports whether a profile name contains filter, ignoring case
+func profileMatches(profile, filter string) bool {
+	return strings.Contains(strings.ToLower(profile), strings.ToLower(filter))
+}
+
+// firstLine returns the first non-empty line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+func TestComplianceFleetSummary(t *testing.T) {
+	const rulePrefix = "xccdf_org.ssgproject.content_rule_"
+	healthy := compliance.OperatorHealthStatus{IsHealthy: true}
+
+	rollup := &compliance.FleetRollup{
+		Clusters: []compliance.ClusterRollup{
+			{
+				Name: "prod",
+				Profiles: map[string]compliance.CheckCounts{
+					"cis":      {Pass: 3, Fail: 1, Total: 4},
+					"cis-node": {Pass: 1, Fail: 1, Total: 2},
+				},
+				OperatorStatus: healthy,
+			},
+			{
+				Name:           "staging",
+				Profiles:       map[string]compliance.CheckCounts{"cis": {Pass: 1, Fail: 2, Total: 3}},
+				OperatorStatus: compliance.OperatorHealthStatus{Issues: []string{"Pod compliance-operator-0 is not ready"}},
+			},
+			{Name: "dev", Err: errors.New("connection refused")},
+			{
+				Name:     "qa",
+				Profiles: map[string]compliance.CheckCounts{"cis": {Pass: 2, Total: 2}},
+				Partial:  &compliance.PartialResult{Reason: "the deadline passed", Skipped: []string{"operator status"}},
+			},
+		},
+		Profiles: []string{"cis", "cis-node"},
+		FailingRules: []compliance.FailingRule{
+			{
+				ID: rulePrefix + "audit_log_path", Severity: "high", Description: "Configure the audit log path\nmore",
+				Clusters: []string{"prod", "staging"}, Profiles: []string{"cis"}, Results: 2,
+				Failures: []compliance.RuleFailure{{Cluster: "prod", Profile: "cis", Results: 1}, {Cluster: "staging", Profile: "cis", Results: 1}},
+			},
+			{
+				ID: rulePrefix + "kubelet_anonymous_auth", Severity: "high",
+				Clusters: []string{"prod", "staging"}, Profiles: []string{"cis", "cis-node"}, Results: 2,
+				Failures: []compliance.RuleFailure{{Cluster: "prod", Profile: "cis-node", Results: 1}, {Cluster: "staging", Profile: "cis", Results: 1}},
+			},
+			{
+				ID: rulePrefix + "etcd_unique_ca", Severity: "medium",
+				Clusters: []string{"staging"}, Profiles: []string{"cis"}, Results: 1,
+				Failures: []compliance.RuleFailure{{Cluster: "staging", Profile: "cis", Results: 1}},
+			},
+		},
+		Timestamp: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	high := "high"
+	node := "node"
+	tests := []struct {
+		name    string
+		args    FleetSummaryArgs
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "whole fleet",
+			want: []string{
+				"**Clusters:** 4 (3 collected)",
+				"| Cluster | cis | cis-node |",
+				"| prod | 75.0% (1 fail) | 50.0% (1 fail) |",
+				"| staging | 33.3% (2 fail) | - |",
+				"| dev | n/a | n/a |",
+				"## Top Failing Rules (3 of 3)",
+				"1. **audit_log_path** 🔴 HIGH: fails in 2 of 4 clusters (prod, staging)\n   Configure the audit log path\n",
+				"2. **kubelet_anonymous_auth** 🔴 HIGH: fails in 2 of 4 clusters (prod, staging)\n",
+				"- **prod**: 2 rules",
+				"- **staging**: 3 rules",
+				"- ❌ **staging**: Pod compliance-operator-0 is not ready",
+				"- ⚠️ **qa**: unknown (not collected)",
+				"- **dev**: connection refused",
+				"- **qa**: incomplete because the deadline passed (skipped: operator status)",
+			},
+			notWant: []string{"no running operator pods", "every collected cluster"},
+		},
+		{
+			name: "severity filter and top rules",
+			args: FleetSummaryArgs{SeverityFilter: &high, TopRules: 1},
+			want: []string{
+				"**Severity filter:** high",
+				"## Top Failing Rules (1 of 2)",
+				"1. **audit_log_path**",
+			},
+			notWant: []string{"kubelet_anonymous_auth", "etcd_unique_ca"},
+		},
+		{
+			name: "profile filter",
+			args: FleetSummaryArgs{Profile: &node},
+			want: []string{
+				"**Profile filter:** node",
+				"| Cluster | cis-node |",
+				"| prod | 50.0% (1 fail) |",
+				"## Top Failing Rules (1 of 1)",
+				"1. **kubelet_anonymous_auth** 🔴 HIGH: fails in 1 of 4 clusters (prod)\n",
+				"- **prod**: 1 rules",
+			},
+			notWant: []string{"audit_log_path", "etcd_unique_ca", "- **staging**: "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComplianceFleetSummary(rollup, tt.args)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("summary does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("summary contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
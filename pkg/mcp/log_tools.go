@@ -10,6 +10,7 @@ import (
 
 // LogsArgs holds arguments for compliance_logs tool
 type LogsArgs struct {
+	Cluster   string  `json:"cluster,omitempty"`
 	PodType   string  `json:"pod_type"`
 	ScanName  *string `json:"scan_name,omitempty"`
 	Namespace string  `json:"namespace"`
@@ -17,10 +18,36 @@ type LogsArgs struct {
 	Analyze   bool    `json:"analyze"`
 }
 
+// LogLimits bounds how much log output the compliance_logs tool returns
+type LogLimits struct {
+	// DefaultTailLines is used when the caller doesn't set tail_lines
+	DefaultTailLines int64
+	// MaxTailLines caps tail_lines
+	MaxTailLines int64
+	// MaxFindings caps the errors and warnings reported per pod
+	MaxFindings int
+}
+
+// DefaultLogLimits returns the log limits used when none are configured
+func DefaultLogLimits() LogLimits {
+	return LogLimits{
+		DefaultTailLines: 100,
+		MaxTailLines:     5000,
+		MaxFindings:      20,
+	}
+}
+
 // ComplianceLogs fetches and analyzes logs from operator and scanner pods
-func ComplianceLogs(ctx context.Context, client *compliance.ComplianceClient, args LogsArgs) (string, error) {
+func ComplianceLogs(ctx context.Context, client *compliance.ComplianceClient, args LogsArgs, limits LogLimits) (string, error) {
 	var output strings.Builder
 
+	if args.TailLines <= 0 {
+		args.TailLines = limits.DefaultTailLines
+	}
+	if limits.MaxTailLines > 0 && args.TailLines > limits.MaxTailLines {
+		args.TailLines = limits.MaxTailLines
+	}
+
 	output.WriteString(fmt.Sprintf("# Logs: %s\n\n", args.PodType))
 
 	var pods []string
@@ -58,8 +85,15 @@ func ComplianceLogs(ctx context.Context, client *compliance.ComplianceClient, ar
 		return "", fmt.Errorf("invalid pod_type: %s (must be 'operator' or 'scanner')", args.PodType)
 	}
 
-	// Fetch logs from each pod
-	for _, podName := range pods {
+	// Fetch logs from each pod, stopping once the call is cancelled or its
+	// deadline passes
+	var partial *compliance.PartialResult
+	for i, podName := range pods {
+		if ctx.Err() != nil {
+			partial = compliance.NewPartialResult(ctx.Err(), podLogsSkipped(pods[i:])...)
+			break
+		}
+
 		output.WriteString(fmt.Sprintf("## Pod: %s\n\n", podName))
 
 		logs, err := client.GetPodLogs(ctx, podName, args.TailLines)
@@ -75,7 +109,7 @@ func ComplianceLogs(ctx context.Context, client *compliance.ComplianceClient, ar
 
 		// Analyze logs if requested
 		if args.Analyze {
-			errors, warnings := analyzeLogs(logs)
+			errors, warnings := analyzeLogs(logs, limits.MaxFindings)
 
 			if len(errors) > 0 {
 				output.WriteString("### Errors Detected:\n")
@@ -104,11 +138,23 @@ func ComplianceLogs(ctx context.Context, client *compliance.ComplianceClient, ar
 		output.WriteString("\n```\n\n")
 	}
 
+	output.WriteString(compliance.FormatPartialResult(partial))
+
 	return output.String(), err
 }
 
-// analyzeLogs analyzes log content for errors and warnings
-func analyzeLogs(logs string) (errors []string, warnings []string) {
+// podLogsSkipped describes the pods whose logs were not fetched
+func podLogsSkipped(pods []string) []string {
+	skipped := make([]string, len(pods))
+	for i, pod := range pods {
+		skipped[i] = fmt.Sprintf("logs for pod %s", pod)
+	}
+	return skipped
+}
+
+// analyzeLogs analyzes log content for errors and warnings, reporting at
+// most maxFindings of each
+func analyzeLogs(logs string, maxFindings int) (errors []string, warnings []string) {
 	lines := strings.Split(logs, "\n")
 
 	errorKeywords := []string{
@@ -159,11 +205,11 @@ func analyzeLogs(logs string) (errors []string, warnings []string) {
 	}
 
 	// Limit results to avoid overwhelming output
-	if len(errors) > 20 {
-		errors = errors[:20]
+	if len(errors) > maxFindings {
+		errors = errors[:maxFindings]
 	}
-	if len(warnings) > 20 {
-		warnings = warnings[:20]
+	if len(warnings) > maxFindings {
+		warnings = warnings[:maxFindings]
 	}
 
 	return errors, warnings
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// loggerName identifies this server's log notifications
+const loggerName = "compliance-mcp"
+
+// sessionLogger forwards compliance log events to the MCP client that made
+// the request as notifications/message. Warnings and errors are also written
+// to the standard logger.
+type sessionLogger struct {
+	mcpServer *server.MCPServer
+}
+
+// Log implements compliance.Logger
+func (l *sessionLogger) Log(ctx context.Context, level compliance.LogLevel, message string, fields map[string]interface{}) {
+	if level == compliance.LogLevelWarning || level == compliance.LogLevelError {
+		log.Printf("%s: %s %v", level, message, fields)
+	}
+
+	data := map[string]interface{}{
+		"message": message,
+	}
+	for key, value := range fields {
+		data[key] = value
+	}
+
+	// Events outside a client request (startup, background work) have no
+	// session to deliver to and are dropped
+	notification := mcp.NewLoggingMessageNotification(toMCPLogLevel(level), loggerName, data)
+	_ = l.mcpServer.SendLogMessageToClient(ctx, notification)
+}
+
+// toMCPLogLevel maps a compliance log level to the MCP logging level
+func toMCPLogLevel(level compliance.LogLevel) mcp.LoggingLevel {
+	switch level {
+	case compliance.LogLevelDebug:
+		return mcp.LoggingLevelDebug
+	case compliance.LogLevelWarning:
+		return mcp.LoggingLevelWarning
+	case compliance.LogLevelError:
+		return mcp.LoggingLevelError
+	default:
+		return mcp.LoggingLevelInfo
+	}
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/xiyuan/compliance-mcp/pkg/audit"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"github.com/xiyuan/compliance-mcp/pkg/metrics"
+)
+
+// DefaultToolTimeout is the deadline applied to tool calls that have no
+// tool-specific timeout
+const DefaultToolTimeout = 2 * time.Minute
+
+// Option configures an MCPServer
+type Option func(*MCPServer)
+
+// WithResponseBudget sets the maximum size in bytes of paginated tool responses
+func WithResponseBudget(budget int) Option {
+	return func(s *MCPServer) {
+		s.responseBudget = budget
+	}
+}
+
+// WithDefaultToolTimeout sets the deadline for tool calls that have no
+// tool-specific timeout. Zero disables the deadline.
+func WithDefaultToolTimeout(timeout time.Duration) Option {
+	return func(s *MCPServer) {
+		s.defaultToolTimeout = timeout
+	}
+}
+
+// WithToolTimeout sets the deadline for calls to a single tool. Zero
+// disables the deadline for that tool.
+func WithToolTimeout(tool string, timeout time.Duration) Option {
+	return func(s *MCPServer) {
+		s.toolTimeouts[tool] = timeout
+	}
+}
+
+// WithImpersonation makes every tool call use a Kubernetes client that
+// impersonates the authenticated caller. Requests without an authenticated
+// caller are rejected.
+func WithImpersonation(enabled bool) Option {
+	return func(s *MCPServer) {
+		s.impersonate = enabled
+	}
+}
+
+// WithClusters makes tool calls query the given clusters instead of the
+// single cluster found by compliance.GetKubeConfig. An empty defaultCluster
+// selects the first cluster.
+func WithClusters(clusters []compliance.Cluster, defaultCluster string) Option {
+	return func(s *MCPServer) {
+		s.clusterConfigs = clusters
+		s.defaultCluster = defaultCluster
+	}
+}
+
+// WithRawResultsExtractor sets the pod compliance_extract_raw_results runs
+// to read raw results PVCs
+func WithRawResultsExtractor(extractor compliance.RawResultsExtractor) Option {
+	return func(s *MCPServer) {
+		s.rawResultsExtractor = extractor
+	}
+}
+
+// WithAuditLogger records every tool invocation in the given audit log
+func WithAuditLogger(logger *audit.Logger) Option {
+	return func(s *MCPServer) {
+		s.audit = logger
+	}
+}
+
+// WithThresholds sets when diagnostics report stuck scans and restarting pods
+func WithThresholds(thresholds compliance.Thresholds) Option {
+	return func(s *MCPServer) {
+		s.thresholds = thresholds
+	}
+}
+
+// WithLogLimits bounds the output of the compliance_logs tool
+func WithLogLimits(limits LogLimits) Option {
+	return func(s *MCPServer) {
+		s.logLimits = limits
+	}
+}
+
+// WithToolPolicy sets which tools are served and which groups may use them.
+// Naming a tool or category that doesn't exist makes NewMCPServer fail.
+func WithToolPolicy(policy ToolPolicy) Option {
+	return func(s *MCPServer) {
+		s.policy = policy
+	}
+}
+
+// WithAllowedNamespaces lets tool calls name these namespaces in addition
+// to the server's default namespace
+func WithAllowedNamespaces(namespaces []string) Option {
+	return func(s *MCPServer) {
+		s.allowedNamespaces = namespaces
+	}
+}
+
+// WithMetrics records tool calls and Kubernetes API calls in m and exports
+// compliance posture gauges collected once per postureInterval by
+// RunPostureMetrics
+func WithMetrics(m *metrics.Metrics, postureInterval time.Duration) Option {
+	return func(s *MCPServer) {
+		s.metrics = m
+		s.postureInterval = postureInterval
+	}
+}
+
+// WithRateLimit limits each client, identified by its authenticated user or
+// else its MCP session, to perSecond tool calls with bursts of burst. Zero
+// perSecond disables rate limiting.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(s *MCPServer) {
+		if perSecond <= 0 {
+			s.rateLimiters = nil
+			return
+		}
+		s.rateLimiters = newClientLimiters(perSecond, burst)
+	}
+}
+
+// WithHeavyToolConcurrency caps how many heavy tools (status overview and
+// diagnose) run at once across all clients. Zero removes the cap.
+func WithHeavyToolConcurrency(max int) Option {
+	return func(s *MCPServer) {
+		if max <= 0 {
+			s.heavySlots = nil
+			return
+		}
+		s.heavySlots = make(chan struct{}, max)
+	}
+}
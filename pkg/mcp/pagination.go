@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultResponseBudget is the default maximum size in bytes of the text
+// returned by a single paginated tool call
+const DefaultResponseBudget = 48 * 1024
+
+// cursorPrefix marks a continuation cursor produced by this server
+const cursorPrefix = "offset:"
+
+// encodeCursor returns an opaque continuation cursor for the given item offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the item offset encoded in a continuation cursor.
+// An empty cursor starts at the first item.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	return offset, nil
+}
+
+// paginateItems renders the summary followed by as many items as fit in the
+// response budget, starting at the position encoded in cursor. Items larger
+// than a quarter of the budget are truncated; a continuation cursor is
+// appended when items remain.
+func paginateItems(summary string, items []string, cursor string, budget int) (string, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+	if offset > len(items) {
+		return "", fmt.Errorf("cursor is past the end of the results (%d items)", len(items))
+	}
+
+	if budget <= 0 {
+		budget = DefaultResponseBudget
+	}
+
+	var output strings.Builder
+	output.WriteString(summary)
+
+	itemBudget := budget / 4
+	end := offset
+	for end < len(items) {
+		item := truncateText(items[end], itemBudget)
+
+		// Always include at least one item so every page makes progress
+		if end > offset && output.Len()+len(item) > budget {
+			break
+		}
+
+		output.WriteString(item)
+		end++
+	}
+
+	if offset == 0 && end == len(items) {
+		return output.String(), nil
+	}
+
+	output.WriteString("---\n\n")
+	output.WriteString(fmt.Sprintf("_Showing items %d-%d of %d._\n", offset+1, end, len(items)))
+	if end < len(items) {
+		output.WriteString(fmt.Sprintf("\nMore results are available. Call again with `cursor` set to `%s` for the next page, or set `item` to a name for its full detail.\n", encodeCursor(end)))
+	}
+
+	return output.String(), nil
+}
+
+// truncateText shortens text to at most limit bytes, marking the cut
+func truncateText(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+
+	const marker = "\n\n_[truncated, request this item by name for full detail]_\n\n"
+	if limit <= len(marker) {
+		return marker
+	}
+
+	return strings.ToValidUTF8(text[:limit-len(marker)], "") + marker
+}
@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty cursor starts at the first item", cursor: "", want: 0},
+		{name: "encoded offset", cursor: encodeCursor(42), want: 42},
+		{name: "not base64", cursor: "offset:42", wantErr: true},
+		{name: "missing prefix", cursor: "NDI", wantErr: true},
+		{name: "not a number", cursor: "b2Zmc2V0OmFi", wantErr: true},
+		{name: "negative offset", cursor: "b2Zmc2V0Oi0x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.cursor)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeCursor(%q) = %d, want an error", tt.cursor, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCursor(%q) error = %v", tt.cursor, err)
+			}
+			if got != tt.want {
+				t.Errorf("decodeCursor(%q) = %d, want %d", tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginateItems(t *testing.T) {
+	items := make([]string, 10)
+	for i := range items {
+		items[i] = fmt.Sprintf("item %d %s\n", i, strings.Repeat("x", 80))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		budget int
+		// wantItems are the indexes of the items on the page
+		wantItems     []int
+		wantCursor    string
+		wantTruncated bool
+		wantErr       string
+	}{
+		{
+			name:      "everything fits",
+			budget:    DefaultResponseBudget,
+			wantItems: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:       "first page",
+			budget:     400,
+			wantItems:  []int{0, 1, 2, 3},
+			wantCursor: encodeCursor(4),
+		},
+		{
+			name:       "middle page",
+			cursor:     encodeCursor(4),
+			budget:     400,
+			wantItems:  []int{4, 5, 6, 7},
+			wantCursor: encodeCursor(8),
+		},
+		{
+			name:      "last page",
+			cursor:    encodeCursor(8),
+			budget:    400,
+			wantItems: []int{8, 9},
+		},
+		{
+			name:          "items over a quarter of the budget are cut",
+			budget:        300,
+			wantItems:     []int{0, 1, 2},
+			wantCursor:    encodeCursor(3),
+			wantTruncated: true,
+		},
+		{
+			name:          "a page always holds one item",
+			budget:        10,
+			wantCursor:    encodeCursor(1),
+			wantTruncated: true,
+		},
+		{
+			name:    "cursor past the end",
+			cursor:  encodeCursor(11),
+			budget:  400,
+			wantErr: "past the end",
+		},
+		{
+			name:    "invalid cursor",
+			cursor:  "bogus",
+			budget:  400,
+			wantErr: "invalid cursor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := paginateItems("# Summary\n\n", items, tt.cursor, tt.budget)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("paginateItems() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("paginateItems() error = %v", err)
+			}
+
+			if !strings.HasPrefix(got, "# Summary\n\n") {
+				t.Errorf("page does not start with the summary: %.40q", got)
+			}
+			for i := range items {
+				want := contains(tt.wantItems, i)
+				if has := strings.Contains(got, fmt.Sprintf("item %d ", i)); has != want {
+					t.Errorf("page has item %d = %t, want %t", i, has, want)
+				}
+			}
+
+			if truncated := strings.Contains(got, "_[truncated"); truncated != tt.wantTruncated {
+				t.Errorf("page has truncated items = %t, want %t", truncated, tt.wantTruncated)
+			}
+
+			hasCursor := strings.Contains(got, "More results are available")
+			if hasCursor != (tt.wantCursor != "") {
+				t.Errorf("page offers a next cursor = %t, want %t", hasCursor, tt.wantCursor != "")
+			}
+			if tt.wantCursor != "" && !strings.Contains(got, "`"+tt.wantCursor+"`") {
+				t.Errorf("page does not offer cursor %s:\n%s", tt.wantCursor, got)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	const marker = "\n\n_[truncated, request this item by name for full detail]_\n\n"
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "within the limit", text: "short", limit: 10, want: "short"},
+		{name: "no limit", text: "short", limit: 0, want: "short"},
+		{name: "cut and marked", text: strings.Repeat("a", 200), limit: len(marker) + 5, want: "aaaaa" + marker},
+		{name: "limit smaller than the marker", text: strings.Repeat("a", 200), limit: 10, want: marker},
+		{name: "multi-byte rune is not split", text: "ab" + strings.Repeat("é", 100), limit: len(marker) + 3, want: "ab" + marker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.text, tt.limit)
+			if got != tt.want {
+				t.Errorf("truncateText() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateText() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func contains(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+)
+
+// Tool categories, usable in place of tool names in a ToolPolicy
+const (
+	CategoryStatus      = "status"
+	CategoryResults     = "results"
+	CategoryLogs        = "logs"
+	CategoryDiagnostics = "diagnostics"
+	// CategoryWrite holds tools that modify the cluster
+	CategoryWrite = "write"
+)
+
+// rejectDenied is the reason reported for calls refused by the tool policy
+const rejectDenied = "denied"
+
+// writeToolNames lists the tools that modify the cluster, so the initialize
+// instructions can name the ones that are served
+var writeToolNames = []string{"compliance_extract_raw_results"}
+
+// ToolPolicy decides which tools are offered and to whom. Entries in every
+// list are tool names or category names.
+type ToolPolicy struct {
+	// Enabled lists the tools to serve; empty serves every tool
+	Enabled []string
+	// Disabled removes tools from the served set
+	Disabled []string
+	// ReadOnly refuses every tool that isn't annotated read-only
+	ReadOnly bool
+	// Groups further restrict the served tools for authenticated callers
+	// by group membership
+	Groups []GroupToolRule
+	// DenyUnmatched refuses every tool to callers no group rule matches
+	// when Groups is set; otherwise they may use every served tool
+	DenyUnmatched bool
+}
+
+// GroupToolRule restricts the tools offered to members of a group. When a
+// caller matches several rules, a tool is offered if any rule allows it and
+// none denies it.
+type GroupToolRule struct {
+	Group string
+	// Allow lists the tools members may use; empty allows every served tool
+	Allow []string
+	// Deny lists tools members may not use
+	Deny []string
+}
+
+// toolInfo records how a registered tool is classified
+type toolInfo struct {
+	category string
+	readOnly bool
+	served   bool
+}
+
+// matchesTool reports whether any entry names the tool or its category
+func matchesTool(entries []string, name, category string) bool {
+	for _, entry := range entries {
+		if entry == name || entry == category {
+			return true
+		}
+	}
+	return false
+}
+
+// toolServed reports whether the deployment-wide policy serves a tool
+func (s *MCPServer) toolServed(name string, info toolInfo) bool {
+	if s.policy.ReadOnly && !info.readOnly {
+		return false
+	}
+	if matchesTool(s.policy.Disabled, name, info.category) {
+		return false
+	}
+	return len(s.policy.Enabled) == 0 || matchesTool(s.policy.Enabled, name, info.category)
+}
+
+// toolAllowedFor reports whether the group rules let the caller use a
+// served tool. Callers matching no rule, including unauthenticated ones,
+// get every served tool or none, as DenyUnmatched says.
+func (s *MCPServer) toolAllowedFor(ctx context.Context, name string) bool {
+	if len(s.policy.Groups) == 0 {
+		return true
+	}
+
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return !s.policy.DenyUnmatched
+	}
+
+	info := s.tools[name]
+	matched, allowed := false, false
+	for _, rule := range s.policy.Groups {
+		if !containsString(user.Groups, rule.Group) {
+			continue
+		}
+		matched = true
+
+		if matchesTool(rule.Deny, name, info.category) {
+			return false
+		}
+		if len(rule.Allow) == 0 || matchesTool(rule.Allow, name, info.category) {
+			allowed = true
+		}
+	}
+
+	if !matched {
+		return !s.policy.DenyUnmatched
+	}
+	return allowed
+}
+
+// filterTools removes the tools the caller may not use from tools/list
+func (s *MCPServer) filterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if s.toolAllowedFor(ctx, tool.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
+
+// withPolicy wraps a tool handler so callers the policy excludes are refused
+// even if they call the tool without listing it
+func (s *MCPServer) withPolicy(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.policy.ReadOnly && !s.tools[name].readOnly {
+			return s.deniedResult(ctx, name, fmt.Errorf("tool %s modifies the cluster and the server is in read-only mode", name)), nil
+		}
+		if !s.toolAllowedFor(ctx, name) {
+			return s.deniedResult(ctx, name, fmt.Errorf("tool %s is not permitted for your groups", name)), nil
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// deniedResult builds the error result for a call refused by the tool
+// policy and counts it with the other rejected calls
+func (s *MCPServer) deniedResult(ctx context.Context, name string, err error) *mcp.CallToolResult {
+	if s.metrics != nil {
+		s.metrics.ObserveRejectedToolCall(name, rejectDenied)
+	}
+
+	result := s.createErrorResult(ctx, err)
+	result.StructuredContent = map[string]interface{}{
+		"error":   rejectDenied,
+		"message": err.Error(),
+	}
+	return result
+}
+
+// checkToolPolicy reports policy entries that name neither a tool nor a
+// category, which are most likely typos in the configuration
+func (s *MCPServer) checkToolPolicy() error {
+	known := make(map[string]bool)
+	var names []string
+	for name, info := range s.tools {
+		known[name] = true
+		known[info.category] = true
+		names = append(names, name)
+	}
+	for _, category := range []string{CategoryStatus, CategoryResults, CategoryLogs, CategoryDiagnostics, CategoryWrite} {
+		known[category] = true
+	}
+	sort.Strings(names)
+
+	check := func(entries []string) error {
+		for _, entry := range entries {
+			if !known[entry] {
+				return fmt.Errorf("unknown tool or category %q in tool configuration (tools: %s; categories: %s, %s, %s, %s, %s)",
+					entry, strings.Join(names, ", "), CategoryStatus, CategoryResults, CategoryLogs, CategoryDiagnostics, CategoryWrite)
+			}
+		}
+		return nil
+	}
+
+	if err := check(s.policy.Enabled); err != nil {
+		return err
+	}
+	if err := check(s.policy.Disabled); err != nil {
+		return err
+	}
+	for _, rule := range s.policy.Groups {
+		if rule.Group == "" {
+			return fmt.Errorf("tool group rule without a group")
+		}
+		if err := check(rule.Allow); err != nil {
+			return err
+		}
+		if err := check(rule.Deny); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+	"github.com/xiyuan/compliance-mcp/pkg/metrics"
+)
+
+// testTools classifies a few tools the way registerTools does, plus a tool
+// that modifies the cluster
+var testTools = map[string]toolInfo{
+	"compliance_suite_status":  {category: CategoryStatus, readOnly: true},
+	"compliance_check_results": {category: CategoryResults, readOnly: true},
+	"compliance_logs":          {category: CategoryLogs, readOnly: true},
+	"compliance_diagnose":      {category: CategoryDiagnostics, readOnly: true},
+	"compliance_rescan":        {category: CategoryWrite},
+}
+
+func TestToolServed(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ToolPolicy
+		want   []string
+	}{
+		{
+			name: "everything by default",
+			want: []string{"compliance_suite_status", "compliance_check_results", "compliance_logs", "compliance_diagnose", "compliance_rescan"},
+		},
+		{
+			name:   "read-only",
+			policy: ToolPolicy{ReadOnly: true},
+			want:   []string{"compliance_suite_status", "compliance_check_results", "compliance_logs", "compliance_diagnose"},
+		},
+		{
+			name:   "enabled by name and category",
+			policy: ToolPolicy{Enabled: []string{"compliance_logs", CategoryStatus}},
+			want:   []string{"compliance_suite_status", "compliance_logs"},
+		},
+		{
+			name:   "disabled wins over enabled",
+			policy: ToolPolicy{Enabled: []string{CategoryStatus, CategoryResults}, Disabled: []string{"compliance_check_results"}},
+			want:   []string{"compliance_suite_status"},
+		},
+		{
+			name:   "disabled category",
+			policy: ToolPolicy{Disabled: []string{CategoryDiagnostics, CategoryWrite}},
+			want:   []string{"compliance_suite_status", "compliance_check_results", "compliance_logs"},
+		},
+		{
+			name:   "read-only wins over enabled",
+			policy: ToolPolicy{ReadOnly: true, Enabled: []string{CategoryWrite, "compliance_logs"}},
+			want:   []string{"compliance_logs"},
+		},
+	}
+
+	order := []string{"compliance_suite_status", "compliance_check_results", "compliance_logs", "compliance_diagnose", "compliance_rescan"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MCPServer{policy: tt.policy}
+
+			var got []string
+			for _, name := range order {
+				if s.toolServed(name, testTools[name]) {
+					got = append(got, name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("served tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolAllowedFor(t *testing.T) {
+	groups := []GroupToolRule{
+		{Group: "viewers", Allow: []string{CategoryStatus, CategoryResults}},
+		{Group: "operators", Deny: []string{CategoryWrite}},
+		{Group: "auditors", Allow: []string{"compliance_logs"}, Deny: []string{"compliance_check_results"}},
+	}
+
+	tests := []struct {
+		name          string
+		groups        []GroupToolRule
+		denyUnmatched bool
+		// user is the caller, nil for an unauthenticated one
+		user *auth.UserInfo
+		tool string
+		want bool
+	}{
+		{name: "no group rules", user: &auth.UserInfo{Name: "alice"}, tool: "compliance_rescan", want: true},
+		{name: "allowed category", groups: groups, user: &auth.UserInfo{Groups: []string{"viewers"}}, tool: "compliance_check_results", want: true},
+		{name: "category not allowed", groups: groups, user: &auth.UserInfo{Groups: []string{"viewers"}}, tool: "compliance_logs", want: false},
+		{name: "empty allow list allows every tool", groups: groups, user: &auth.UserInfo{Groups: []string{"operators"}}, tool: "compliance_diagnose", want: true},
+		{name: "denied category", groups: groups, user: &auth.UserInfo{Groups: []string{"operators"}}, tool: "compliance_rescan", want: false},
+		{name: "any matching rule allows", groups: groups, user: &auth.UserInfo{Groups: []string{"viewers", "auditors"}}, tool: "compliance_logs", want: true},
+		{name: "any matching rule denies", groups: groups, user: &auth.UserInfo{Groups: []string{"viewers", "auditors"}}, tool: "compliance_check_results", want: false},
+		{name: "caller in no group", groups: groups, user: &auth.UserInfo{Groups: []string{"developers"}}, tool: "compliance_rescan", want: true},
+		{name: "unauthenticated caller", groups: groups, tool: "compliance_rescan", want: true},
+		{name: "caller in no group denied", groups: groups, denyUnmatched: true, user: &auth.UserInfo{Groups: []string{"developers"}}, tool: "compliance_suite_status", want: false},
+		{name: "unauthenticated caller denied", groups: groups, denyUnmatched: true, tool: "compliance_suite_status", want: false},
+		{name: "matching rule applies when denying the rest", groups: groups, denyUnmatched: true, user: &auth.UserInfo{Groups: []string{"viewers"}}, tool: "compliance_suite_status", want: true},
+		{name: "no group rules when denying the rest", denyUnmatched: true, user: &auth.UserInfo{Name: "alice"}, tool: "compliance_rescan", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MCPServer{policy: ToolPolicy{Groups: tt.groups, DenyUnmatched: tt.denyUnmatched}, tools: testTools}
+
+			ctx := context.Background()
+			if tt.user != nil {
+				ctx = auth.WithUser(ctx, tt.user)
+			}
+			if got := s.toolAllowedFor(ctx, tt.tool); got != tt.want {
+				t.Errorf("toolAllowedFor(%s) = %t, want %t", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ToolPolicy
+		groups  []string
+		tool    string
+		wantErr string
+	}{
+		{
+			name: "allowed",
+			tool: "compliance_rescan",
+		},
+		{
+			name:    "write tool in read-only mode",
+			policy:  ToolPolicy{ReadOnly: true},
+			tool:    "compliance_rescan",
+			wantErr: "the server is in read-only mode",
+		},
+		{
+			name:    "tool not permitted for the caller's groups",
+			policy:  ToolPolicy{Groups: []GroupToolRule{{Group: "viewers", Allow: []string{CategoryStatus}}}},
+			groups:  []string{"viewers"},
+			tool:    "compliance_logs",
+			wantErr: "not permitted for your groups",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			s.policy = tt.policy
+			s.tools = testTools
+			s.metrics = metrics.New()
+
+			called := false
+			handler := s.withPolicy(tt.tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				return mcp.NewToolResultText("ok"), nil
+			})
+
+			ctx := auth.WithUser(context.Background(), &auth.UserInfo{Name: "alice", Groups: tt.groups})
+			result, err := handler(ctx, mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if tt.wantErr == "" {
+				if !called || result.IsError {
+					t.Errorf("call was refused: %+v", result)
+				}
+				return
+			}
+			if called {
+				t.Errorf("refused tool ran")
+			}
+			if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, tt.wantErr) {
+				t.Errorf("result = %+v, want an error containing %q", result, tt.wantErr)
+			}
+			if content, _ := result.StructuredContent.(map[string]interface{}); content["error"] != rejectDenied {
+				t.Errorf("structured content = %v, want error %s", result.StructuredContent, rejectDenied)
+			}
+
+			// Refusals are counted with the other rejected calls
+			recorder := httptest.NewRecorder()
+			s.metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			want := `compliance_mcp_tool_calls_rejected_total{reason="denied",tool="` + tt.tool + `"} 1`
+			if !strings.Contains(recorder.Body.String(), want) {
+				t.Errorf("metrics do not contain %s", want)
+			}
+		})
+	}
+}
+
+func TestCheckToolPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ToolPolicy
+		wantErr string
+	}{
+		{
+			name: "tools and categories",
+			policy: ToolPolicy{
+				Enabled:  []string{CategoryStatus, "compliance_logs"},
+				Disabled: []string{CategoryWrite},
+				Groups:   []GroupToolRule{{Group: "viewers", Allow: []string{CategoryResults}, Deny: []string{"compliance_diagnose"}}},
+			},
+		},
+		{
+			name:    "unknown enabled tool",
+			policy:  ToolPolicy{Enabled: []string{"compliance_logz"}},
+			wantErr: `unknown tool or category "compliance_logz"`,
+		},
+		{
+			name:    "unknown disabled category",
+			policy:  ToolPolicy{Disabled: []string{"writes"}},
+			wantErr: `unknown tool or category "writes"`,
+		},
+		{
+			name:    "unknown tool in a group rule",
+			policy:  ToolPolicy{Groups: []GroupToolRule{{Group: "viewers", Deny: []string{"rescan"}}}},
+			wantErr: `unknown tool or category "rescan"`,
+		},
+		{
+			name:    "group rule without a group",
+			policy:  ToolPolicy{Groups: []GroupToolRule{{Allow: []string{CategoryStatus}}}},
+			wantErr: "tool group rule without a group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MCPServer{policy: tt.policy, tools: testTools}
+			err := s.checkToolPolicy()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkToolPolicy() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkToolPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"golang.org/x/time/rate"
+)
+
+// Reasons a tool call is rejected before it runs
+const (
+	rejectRateLimited = "rate_limited"
+	rejectBusy        = "busy"
+)
+
+// limiterIdleTTL is how long an unused per-client limiter is kept
+const limiterIdleTTL = 10 * time.Minute
+
+// heavyToolRetryAfter is the retry hint returned when every heavy tool slot
+// is busy
+const heavyToolRetryAfter = 5 * time.Second
+
+// heavyTools list every scan's results or run every analysis, so the number
+// running at once is capped. The README's rate limiting section lists them
+// for operators; keep the two in step.
+var heavyTools = map[string]bool{
+	"compliance_status_overview":     true,
+	"compliance_diagnose":            true,
+	"compliance_fleet_summary":       true,
+	"compliance_export":              true,
+	"compliance_report":              true,
+	"compliance_controls":            true,
+	"compliance_extract_raw_results": true,
+}
+
+// clientLimiters holds a token bucket per client
+type clientLimiters struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]*clientLimiter
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func newClientLimiters(perSecond float64, burst int) *clientLimiters {
+	return &clientLimiters{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		limiters: make(map[string]*clientLimiter),
+	}
+}
+
+// allow takes a token from the client's bucket. If none is available it
+// returns false and how long until one will be.
+func (l *clientLimiters) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for key, cached := range l.limiters {
+		if now.Sub(cached.lastUsed) > limiterIdleTTL {
+			delete(l.limiters, key)
+		}
+	}
+
+	cached, ok := l.limiters[client]
+	if !ok {
+		cached = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[client] = cached
+	}
+	cached.lastUsed = now
+
+	reservation := cached.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// clientKey identifies the caller for rate limiting: the authenticated user,
+// else the MCP session
+func clientKey(ctx context.Context) string {
+	if user, ok := auth.UserFromContext(ctx); ok {
+		return "user:" + user.Name
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return "session:" + session.SessionID()
+	}
+	return "anonymous"
+}
+
+// withLimits wraps a tool handler so calls beyond the caller's rate, or
+// heavy calls beyond the concurrency cap, are rejected with a retry hint
+// instead of waiting
+func (s *MCPServer) withLimits(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.rateLimiters != nil {
+			if ok, retryAfter := s.rateLimiters.allow(clientKey(ctx)); !ok {
+				return s.rejectedResult(ctx, name, rejectRateLimited, "rate limit exceeded for this client", retryAfter), nil
+			}
+		}
+
+		if s.heavySlots != nil && heavyTools[name] {
+			select {
+			case s.heavySlots <- struct{}{}:
+				defer func() { <-s.heavySlots }()
+			default:
+				message := fmt.Sprintf("too many heavy tool calls in progress (limit %d)", cap(s.heavySlots))
+				return s.rejectedResult(ctx, name, rejectBusy, message, heavyToolRetryAfter), nil
+			}
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// rejectedResult builds the error result for a call that was not run. The
+// structured content lets clients back off without parsing the text.
+func (s *MCPServer) rejectedResult(ctx context.Context, name, reason, message string, retryAfter time.Duration) *mcp.CallToolResult {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	if s.metrics != nil {
+		s.metrics.ObserveRejectedToolCall(name, reason)
+	}
+	s.logger.Log(ctx, compliance.LogLevelWarning, "Tool call rejected", map[string]interface{}{
+		"tool":              name,
+		"reason":            reason,
+		"retryAfterSeconds": seconds,
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Error: %s; retry after %d seconds", message, seconds),
+			},
+		},
+		StructuredContent: map[string]interface{}{
+			"error":             reason,
+			"message":           message,
+			"retryAfterSeconds": seconds,
+		},
+		IsError: true,
+	}
+}
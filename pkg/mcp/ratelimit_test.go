@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
+)
+
+// newTestServer returns an MCPServer with no Kubernetes client, enough to
+// exercise the tool handler wrappers
+func newTestServer(opts ...Option) *MCPServer {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithLogging())
+	s := &MCPServer{
+		mcpServer:    mcpServer,
+		logger:       &sessionLogger{mcpServer: mcpServer},
+		toolTimeouts: make(map[string]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func TestClientLimitersAllow(t *testing.T) {
+	limiters := newClientLimiters(1, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiters.allow("user:alice"); !ok {
+			t.Fatalf("call %d within the burst was rejected", i+1)
+		}
+	}
+
+	ok, retryAfter := limiters.allow("user:alice")
+	if ok {
+		t.Fatalf("call beyond the burst was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retry after %s, want at most a second", retryAfter)
+	}
+
+	// Rejected calls don't use up tokens, and each client has its own bucket
+	if ok, _ := limiters.allow("user:bob"); !ok {
+		t.Errorf("another client was rejected")
+	}
+}
+
+func TestClientLimitersPruneIdle(t *testing.T) {
+	limiters := newClientLimiters(1, 1)
+	limiters.allow("user:alice")
+	limiters.limiters["user:alice"].lastUsed = time.Now().Add(-2 * limiterIdleTTL)
+
+	limiters.allow("user:bob")
+	if _, ok := limiters.limiters["user:alice"]; ok {
+		t.Errorf("idle limiter was not pruned")
+	}
+	if _, ok := limiters.limiters["user:bob"]; !ok {
+		t.Errorf("active limiter was pruned")
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	if got := clientKey(context.Background()); got != "anonymous" {
+		t.Errorf("clientKey() = %q without a caller, want anonymous", got)
+	}
+
+	ctx := auth.WithUser(context.Background(), &auth.UserInfo{Name: "alice"})
+	if got := clientKey(ctx); got != "user:alice" {
+		t.Errorf("clientKey() = %q, want user:alice", got)
+	}
+}
+
+func TestWithLimitsRateLimited(t *testing.T) {
+	s := newTestServer(WithRateLimit(0.001, 1))
+	handler := s.withLimits("compliance_suite_status", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	ctx := auth.WithUser(context.Background(), &auth.UserInfo{Name: "alice"})
+
+	result, err := handler(ctx, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("first call = %+v, %v, want success", result, err)
+	}
+
+	result, err = handler(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("second call error = %v", err)
+	}
+	assertRejected(t, result, rejectRateLimited)
+}
+
+func TestWithLimitsHeavyTools(t *testing.T) {
+	s := newTestServer(WithHeavyToolConcurrency(1))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	}
+	quick := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+
+	done := make(chan *mcp.CallToolResult)
+	go func() {
+		result, _ := s.withLimits("compliance_diagnose", blocking)(context.Background(), mcp.CallToolRequest{})
+		done <- result
+	}()
+	<-started
+
+	// The only slot is taken, so another heavy tool is rejected while other
+	// tools still run
+	result, _ := s.withLimits("compliance_status_overview", quick)(context.Background(), mcp.CallToolRequest{})
+	assertRejected(t, result, rejectBusy)
+
+	result, _ = s.withLimits("compliance_suite_status", quick)(context.Background(), mcp.CallToolRequest{})
+	if result.IsError {
+		t.Errorf("light tool was rejected while a heavy tool ran")
+	}
+
+	close(release)
+	if result := <-done; result.IsError {
+		t.Errorf("heavy tool holding the slot failed")
+	}
+
+	result, _ = s.withLimits("compliance_status_overview", quick)(context.Background(), mcp.CallToolRequest{})
+	if result.IsError {
+		t.Errorf("heavy tool was rejected after the slot was released")
+	}
+}
+
+// assertRejected checks that result is a rejection for reason with a retry
+// hint clients can read without parsing the text
+func assertRejected(t *testing.T, result *mcp.CallToolResult, reason string) {
+	t.Helper()
+
+	if !result.IsError {
+		t.Fatalf("result is not an error: %+v", result)
+	}
+	content, ok := result.StructuredContent.(map[string]interface{})
+	if !ok {
+		t.Fatalf("structured content = %T, want a map", result.StructuredContent)
+	}
+	if content["error"] != reason {
+		t.Errorf("error = %v, want %s", content["error"], reason)
+	}
+	if seconds, _ := content["retryAfterSeconds"].(int); seconds < 1 {
+		t.Errorf("retryAfterSeconds = %v, want at least 1", content["retryAfterSeconds"])
+	}
+}
@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// maxEvidenceMessages caps the scanner messages shown per rule and file
+const maxEvidenceMessages = 10
+
+// RawResultsArgs holds arguments for compliance_raw_results and
+// compliance_extract_raw_results tools
+type RawResultsArgs struct {
+	Cluster   string  `json:"cluster,omitempty"`
+	ScanName  string  `json:"scan_name"`
+	Namespace string  `json:"namespace"`
+	Node      *string `json:"node,omitempty"`
+	Rule      *string `json:"rule,omitempty"`
+}
+
+// rawResultsSchema is the input schema shared by the raw results tools
+func rawResultsSchema(s *MCPServer) mcp.ToolInputSchema {
+	return mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"cluster": s.clusterProperty(),
+			"scan_name": map[string]interface{}{
+				"type":        "string",
+				"description": "Scan to read raw results for",
+			},
+			"namespace": map[string]interface{}{
+				"type":        "string",
+				"description": "Namespace",
+				"default":     s.namespace,
+			},
+			"node": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional: only result files from nodes whose name contains this",
+			},
+			"rule": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional: return the raw evidence for this rule (XCCDF rule ID, rule name or check result name) instead of listing files",
+			},
+		},
+		Required: []string{"scan_name"},
+	}
+}
+
+// ComplianceRawResults lists a scan's raw result files or the evidence they
+// hold for one rule. Files are read from the result ConfigMaps, or from the
+// raw results PVC when extractor is set.
+func ComplianceRawResults(ctx context.Context, client *compliance.ComplianceClient, args RawResultsArgs, extractor *compliance.RawResultsExtractor) (string, error) {
+	if args.ScanName == "" {
+		return "", fmt.Errorf("scan_name is required")
+	}
+
+	var (
+		files     []compliance.RawResultFile
+		truncated bool
+		err       error
+	)
+	if extractor != nil {
+		files, truncated, err = client.ExtractRawResults(ctx, args.ScanName, *extractor)
+	} else {
+		files, err = client.GetRawResults(ctx, args.ScanName)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if args.Node != nil && *args.Node != "" {
+		var filtered []compliance.RawResultFile
+		for _, file := range files {
+			if strings.Contains(file.Node, *args.Node) || strings.Contains(file.Name, *args.Node) {
+				filtered = append(filtered, file)
+			}
+		}
+		files = filtered
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# Raw Results: %s\n\n", args.ScanName))
+	if truncated {
+		output.WriteString("⚠️ The latest run's files exceed the extractor's size cap; only the first ones were read.\n\n")
+	}
+
+	if len(files) == 0 {
+		output.WriteString("No raw result files found")
+		if args.Node != nil && *args.Node != "" {
+			output.WriteString(fmt.Sprintf(" for nodes matching %q", *args.Node))
+		}
+		if extractor == nil {
+			output.WriteString(". The operator may not keep result ConfigMaps once results are aggregated; compliance_extract_raw_results reads the raw results PVC instead, when write features are enabled.\n")
+		} else {
+			output.WriteString(" on the raw results PVC.\n")
+		}
+		return output.String(), nil
+	}
+
+	if args.Rule != nil && *args.Rule != "" {
+		formatRuleEvidence(&output, files, args.ScanName, *args.Rule)
+		return output.String(), nil
+	}
+
+	output.WriteString(fmt.Sprintf("**Source:** %s\n", files[0].Source))
+	output.WriteString(fmt.Sprintf("**Files:** %d\n\n", len(files)))
+	output.WriteString("| File | Node | Compressed | Size | Rule Results |\n")
+	output.WriteString("|------|------|------------|------|--------------|\n")
+	for _, file := range files {
+		node := file.Node
+		if node == "" {
+			node = "-"
+		}
+
+		results := "unreadable"
+		if summary, err := compliance.SummarizeRawResult(file.Content); err == nil {
+			results = formatResultCounts(summary)
+		}
+
+		output.WriteString(fmt.Sprintf("| %s | %s | %t | %s | %s |\n", file.Name, node, file.Compressed, formatSize(len(file.Content)), results))
+	}
+	output.WriteString("\nPass `rule` to see what each file recorded for a rule.\n")
+
+	return output.String(), nil
+}
+
+// formatRuleEvidence writes what every file recorded for a rule
+func formatRuleEvidence(output *strings.Builder, files []compliance.RawResultFile, scanName, rule string) {
+	output.WriteString(fmt.Sprintf("**Rule:** %s\n\n", rule))
+
+	found := false
+	for _, file := range files {
+		evidence, err := compliance.FindRuleEvidence(file.Content, scanName, rule)
+		if err != nil {
+			output.WriteString(fmt.Sprintf("## %s\n\n❌ %v\n\n", file.Name, err))
+			continue
+		}
+		if len(evidence) == 0 {
+			continue
+		}
+		found = true
+
+		title := file.Name
+		if file.Node != "" {
+			title = fmt.Sprintf("%s (node %s)", file.Name, file.Node)
+		}
+		output.WriteString(fmt.Sprintf("## %s\n\n", title))
+
+		for _, item := range evidence {
+			output.WriteString(fmt.Sprintf("### %s\n\n", item.RuleID))
+			output.WriteString(fmt.Sprintf("- **Result:** %s\n", item.Result))
+			if item.Severity != "" {
+				output.WriteString(fmt.Sprintf("- **Severity:** %s\n", item.Severity))
+			}
+			if item.Time != "" {
+				output.WriteString(fmt.Sprintf("- **Evaluated:** %s\n", item.Time))
+			}
+			if item.Check != "" {
+				check := item.Check
+				if item.CheckResult != "" {
+					check += " = " + item.CheckResult
+				}
+				output.WriteString(fmt.Sprintf("- **OVAL definition:** %s\n", check))
+			}
+			if len(item.Tests) > 0 {
+				output.WriteString("- **OVAL tests:**\n")
+				for _, test := range item.Tests {
+					output.WriteString(fmt.Sprintf("  - %s\n", test))
+				}
+			}
+			for i, message := range item.Messages {
+				if i == maxEvidenceMessages {
+					output.WriteString(fmt.Sprintf("- ... %d more messages\n", len(item.Messages)-maxEvidenceMessages))
+					break
+				}
+				output.WriteString(fmt.Sprintf("- **Message:** %s\n", message))
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	if !found {
+		output.WriteString("No file has a result for this rule.\n")
+	}
+}
+
+// formatResultCounts formats rule result counts, e.g. "412 (pass 300, fail 12)"
+func formatResultCounts(summary compliance.RawResultSummary) string {
+	results := make([]string, 0, len(summary.Results))
+	for result := range summary.Results {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+
+	parts := make([]string, 0, len(results))
+	for _, result := range results {
+		parts = append(parts, fmt.Sprintf("%s %d", result, summary.Results[result]))
+	}
+	return fmt.Sprintf("%d (%s)", summary.Rules, strings.Join(parts, ", "))
+}
+
+// formatSize formats a byte count for display
+func formatSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
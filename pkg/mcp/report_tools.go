@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+// reportToolName is the tool behind the /report/{suite} endpoint
+const reportToolName = "compliance_report"
+
+// ReportArgs holds arguments for compliance_report tool
+type ReportArgs struct {
+	Cluster   string `json:"cluster,omitempty"`
+	SuiteName string `json:"suite_name,omitempty"`
+	ScanName  string `json:"scan_name,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+// ComplianceReport renders an HTML compliance report for a suite or a scan.
+// It returns a short summary and the page as an embedded resource.
+func ComplianceReport(ctx context.Context, client *compliance.ComplianceClient, cluster string, args ReportArgs) (string, mcp.TextResourceContents, error) {
+	data, err := client.CollectExport(ctx, cluster, args.SuiteName, args.ScanName)
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	report := compliance.NewReport(data, time.Now())
+	page, err := report.HTML()
+	if err != nil {
+		return "", mcp.TextResourceContents{}, err
+	}
+
+	summary := fmt.Sprintf("HTML compliance report for %s in cluster %s: %.1f%% compliant, %d failing rules across %d scans.",
+		data.Title(), cluster, report.Compliance, len(report.FailingRules), len(report.Scans))
+
+	resource := mcp.TextResourceContents{
+		URI:      data.URI("report.html"),
+		MIMEType: compliance.ReportMediaType,
+		Text:     string(page),
+	}
+	return summary, resource, nil
+}
+
+// ReportHandler serves GET /report/{suite} by calling compliance_report, so
+// the page is subject to the same tool policy, limits, metrics and audit
+// log as the tool. The cluster and namespace query parameters select where
+// the suite is.
+func (s *MCPServer) ReportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tool := s.mcpServer.GetTool(reportToolName)
+		if tool == nil {
+			http.Error(w, "the compliance_report tool is not enabled on this server", http.StatusNotFound)
+			return
+		}
+
+		var request mcp.CallToolRequest
+		request.Params.Name = reportToolName
+		arguments := map[string]interface{}{"suite_name": r.PathValue("suite")}
+		for _, param := range []string{"cluster", "namespace"} {
+			if value := r.URL.Query().Get(param); value != "" {
+				arguments[param] = value
+			}
+		}
+		request.Params.Arguments = arguments
+
+		result, err := tool.Handler(r.Context(), request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if result.IsError {
+			writeReportError(w, result)
+			return
+		}
+
+		for _, content := range result.Content {
+			if embedded, ok := content.(mcp.EmbeddedResource); ok {
+				if page, ok := embedded.Resource.(mcp.TextResourceContents); ok {
+					w.Header().Set("Content-Type", page.MIMEType+"; charset=utf-8")
+					fmt.Fprint(w, page.Text)
+					return
+				}
+			}
+		}
+		http.Error(w, "the report tool returned no page", http.StatusInternalServerError)
+	})
+}
+
+// writeReportError turns a tool error result into an HTTP error. Calls the
+// tool policy or RBAC refuses are forbidden, a suite that doesn't exist is
+// not found, and rejections by the rate limit, the heavy tool cap or
+// shutdown keep their retry hint.
+func writeReportError(w http.ResponseWriter, result *mcp.CallToolResult) {
+	message := "report failed"
+	if len(result.Content) > 0 {
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			message = text.Text
+		}
+	}
+
+	status := http.StatusInternalServerError
+	if structured, ok := result.StructuredContent.(map[string]interface{}); ok {
+		switch structured["error"] {
+		case rejectRateLimited:
+			status = http.StatusTooManyRequests
+		case rejectBusy, rejectShuttingDown:
+			status = http.StatusServiceUnavailable
+		case rejectDenied, errorForbidden:
+			status = http.StatusForbidden
+		case errorNotFound:
+			status = http.StatusNotFound
+		}
+		if seconds, ok := structured["retryAfterSeconds"].(int); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+
+	http.Error(w, message, status)
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestReportHandler(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "compliance.openshift.io", Resource: "compliancesuites"}, "missing")
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "compliance.openshift.io", Resource: "compliancesuites"}, "cis", errors.New("no RBAC"))
+
+	tests := []struct {
+		name           string
+		result         func(s *MCPServer, ctx context.Context) *mcp.CallToolResult
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name: "page",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return createResourceResult("report", mcp.TextResourceContents{MIMEType: compliance.ReportMediaType, Text: "<html></html>"})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "denied by the tool policy",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return s.deniedResult(ctx, reportToolName, errors.New("tool compliance_report is not permitted for your groups"))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "forbidden by RBAC",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return s.createErrorResult(ctx, fmt.Errorf("failed to get compliance suite cis: %w", forbidden))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "unknown suite",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return s.createErrorResult(ctx, fmt.Errorf("failed to get compliance suite missing: %w", notFound))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "rate limited",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return s.rejectedResult(ctx, reportToolName, rejectRateLimited, "rate limit exceeded for this client", 3*time.Second)
+			},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "3",
+		},
+		{
+			name: "other error",
+			result: func(s *MCPServer, ctx context.Context) *mcp.CallToolResult {
+				return s.createErrorResult(ctx, errors.New("connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			s.mcpServer.AddTool(mcp.NewTool(reportToolName), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if suite := request.GetArguments()["suite_name"]; suite != "cis" {
+					t.Errorf("suite_name = %v, want cis", suite)
+				}
+				return tt.result(s, ctx), nil
+			})
+
+			mux := http.NewServeMux()
+			mux.Handle("GET /report/{suite}", s.ReportHandler())
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/report/cis", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if got := recorder.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
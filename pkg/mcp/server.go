@@ -3,52 +3,134 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/xiyuan/compliance-mcp/pkg/audit"
+	"github.com/xiyuan/compliance-mcp/pkg/auth"
 	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	"github.com/xiyuan/compliance-mcp/pkg/metrics"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // MCPServer wraps the MCP server with compliance-specific functionality
 type MCPServer struct {
-	mcpServer  *server.MCPServer
-	client     *compliance.ComplianceClient
-	collector  *compliance.Collector
-	analyzer   *compliance.Analyzer
-	namespace  string
+	mcpServer *server.MCPServer
+	client    *compliance.ComplianceClient
+	logger    compliance.Logger
+	namespace string
+	calls     *callTracker
+	// impersonate enables per-caller clients that act as the authenticated
+	// user
+	impersonate bool
+	// clusters holds the clients for every configured cluster; client is
+	// the default cluster's
+	clusterConfigs []compliance.Cluster
+	clusters       map[string]*clusterClients
+	clusterNames   []string
+	defaultCluster string
+	// audit records every tool invocation when set
+	audit *audit.Logger
+	// responseBudget caps the size in bytes of paginated tool responses
+	responseBudget int
+	// defaultToolTimeout and toolTimeouts bound how long a tool call may run
+	defaultToolTimeout time.Duration
+	toolTimeouts       map[string]time.Duration
+	// thresholds and logLimits tune diagnostics and log output
+	thresholds compliance.Thresholds
+	logLimits  LogLimits
+	// rawResultsExtractor runs the pod that reads raw results PVCs
+	rawResultsExtractor compliance.RawResultsExtractor
+	// policy selects the tools that are served and who may use them
+	policy ToolPolicy
+	// allowedNamespaces may be named by tool calls besides namespace
+	allowedNamespaces []string
+	// tools classifies every tool, served or not
+	tools map[string]toolInfo
+	// metrics records tool and API calls when set
+	metrics         *metrics.Metrics
+	postureInterval time.Duration
+	// posture collects the compliance posture gauges when metrics are set
+	posture *metrics.PostureCollector
+	// rateLimiters and heavySlots reject calls beyond the per-client rate
+	// and the heavy tool concurrency cap when set
+	rateLimiters *clientLimiters
+	heavySlots   chan struct{}
+	// drainer tracks running calls for graceful shutdown
+	drainer *drainer
 }
 
 // NewMCPServer creates a new MCP server for compliance
-func NewMCPServer(namespace string) (*MCPServer, error) {
-	// Create compliance client
-	client, err := compliance.NewComplianceClient(namespace)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create compliance client: %w", err)
+func NewMCPServer(namespace string, opts ...Option) (*MCPServer, error) {
+	// Track in-flight calls so client cancellations can stop them
+	calls := newCallTracker()
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(calls.beforeCallTool)
+
+	s := &MCPServer{
+		namespace: namespace,
+		calls:     calls,
+		drainer:   newDrainer(),
+		tools:     make(map[string]toolInfo),
+
+		responseBudget:     DefaultResponseBudget,
+		defaultToolTimeout: DefaultToolTimeout,
+		toolTimeouts:       make(map[string]time.Duration),
+		thresholds:         compliance.DefaultThresholds(),
+		logLimits:          DefaultLogLimits(),
+		rawResultsExtractor: compliance.RawResultsExtractor{
+			Image:   compliance.DefaultExtractorImage,
+			Timeout: compliance.DefaultExtractorTimeout,
+		},
 	}
 
-	// Create collector and analyzer
-	collector := compliance.NewCollector(client)
-	analyzer := compliance.NewAnalyzer(client)
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.setupClusters(); err != nil {
+		return nil, err
+	}
 
-	// Create MCP server
+	// Create MCP server. tools/list only shows each caller the tools its
+	// groups may use.
 	mcpServer := server.NewMCPServer(
 		"Compliance MCP Server",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		server.WithInstructions(s.buildInstructions()),
+		server.WithToolFilter(s.filterTools),
 	)
+	mcpServer.AddNotificationHandler("notifications/cancelled", calls.handleCancelled)
+	s.mcpServer = mcpServer
 
-	s := &MCPServer{
-		mcpServer: mcpServer,
-		client:    client,
-		collector: collector,
-		analyzer:  analyzer,
-		namespace: namespace,
+	// Report API calls and collection errors to the requesting client
+	logger := &sessionLogger{mcpServer: mcpServer}
+	s.logger = logger
+
+	if s.metrics != nil {
+		s.setClusterLogger(logger, s.metrics)
+
+		// Posture gauges use the server's own identity in the default
+		// cluster, not a caller's
+		s.posture = metrics.NewPostureCollector(s.newCollector(s.client), s.postureInterval, s.defaultToolTimeout)
+		if err := s.metrics.Register(s.posture); err != nil {
+			return nil, fmt.Errorf("failed to register compliance posture metrics: %w", err)
+		}
+	} else {
+		s.setClusterLogger(logger, nil)
 	}
 
 	// Register all tools
-	s.registerTools()
+	if err := s.registerTools(); err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
@@ -58,15 +140,182 @@ func (s *MCPServer) GetServer() *server.MCPServer {
 	return s.mcpServer
 }
 
+// Client returns the compliance client that acts with the server's own
+// identity in the default cluster and namespace
+func (s *MCPServer) Client() *compliance.ComplianceClient {
+	return s.client
+}
+
+// RunPostureMetrics collects the compliance posture gauges once per posture
+// interval until ctx is done. It returns at once when metrics are disabled.
+func (s *MCPServer) RunPostureMetrics(ctx context.Context) {
+	if s.posture != nil {
+		s.posture.Run(ctx)
+	}
+}
+
+// namespaceAllowed reports whether tool calls may name a namespace
+func (s *MCPServer) namespaceAllowed(namespace string) bool {
+	if namespace == s.namespace {
+		return true
+	}
+	for _, allowed := range s.allowedNamespaces {
+		if namespace == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// newCollector creates a collector for a request using the configured thresholds
+func (s *MCPServer) newCollector(client *compliance.ComplianceClient) *compliance.Collector {
+	collector := compliance.NewCollector(client)
+	collector.SetThresholds(s.thresholds)
+	return collector
+}
+
+// newAnalyzer creates an analyzer for a request using the configured thresholds
+func (s *MCPServer) newAnalyzer(client *compliance.ComplianceClient) *compliance.Analyzer {
+	analyzer := compliance.NewAnalyzer(client)
+	analyzer.SetThresholds(s.thresholds)
+	return analyzer
+}
+
+// buildInstructions describes the server to clients in the initialize response
+func (s *MCPServer) buildInstructions() string {
+	var output strings.Builder
+
+	output.WriteString("This server inspects the OpenShift Compliance Operator and reports on compliance suites, scans, check results, remediations, operator logs and common operator issues.\n\n")
+	output.WriteString(fmt.Sprintf("Operator namespace: %s\n", s.client.Namespace()))
+	if len(s.clusterNames) > 1 {
+		output.WriteString(fmt.Sprintf("Clusters: %s (default: %s). Every tool takes a cluster argument; compliance_clusters shows which are reachable.\n", strings.Join(s.clusterNames, ", "), s.defaultCluster))
+	} else {
+		output.WriteString(fmt.Sprintf("Cluster API server: %s\n", s.client.Host()))
+	}
+	var writeTools []string
+	for _, name := range writeToolNames {
+		if s.toolServed(name, toolInfo{category: CategoryWrite}) {
+			writeTools = append(writeTools, name)
+		}
+	}
+	if len(writeTools) == 0 {
+		output.WriteString("Write features: none enabled. All tools are read-only and never modify the cluster.\n\n")
+	} else {
+		output.WriteString(fmt.Sprintf("Write features: %s. These create short-lived objects in the cluster; every other tool is read-only.\n\n", strings.Join(writeTools, ", ")))
+	}
+	output.WriteString("Start with compliance_status_overview for a summary, then drill down with compliance_scan_details and compliance_check_results. Use compliance_diagnose and compliance_logs when scans are stuck or failing.\n")
+
+	return output.String()
+}
+
+// readOnlyAnnotations returns the annotations for a tool that only reads
+// cluster state
+func readOnlyAnnotations(title string) mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// writeAnnotations returns the annotations for a tool that creates
+// short-lived objects in the cluster but changes nothing that exists
+func writeAnnotations(title string) mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(false),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// addTool registers a tool in a category with its handler bounded by the
+// tool's deadline, client cancellation, the tool policy, the rate and
+// concurrency limits and shutdown, and recorded in metrics and the audit log
+// if enabled. Tools the policy doesn't serve are skipped.
+func (s *MCPServer) addTool(category string, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	info := toolInfo{
+		category: category,
+		readOnly: tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint,
+	}
+	info.served = s.toolServed(tool.Name, info)
+	s.tools[tool.Name] = info
+	if !info.served {
+		return
+	}
+
+	if s.metrics != nil {
+		handler = s.withMetrics(tool.Name, handler)
+	}
+	handler = s.withDeadline(tool.Name, handler)
+	handler = s.withLimits(tool.Name, handler)
+	handler = s.withPolicy(tool.Name, handler)
+	handler = s.withDrain(tool.Name, handler)
+	if s.audit != nil {
+		handler = s.withAudit(tool.Name, handler)
+	}
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// withDeadline wraps a tool handler so it stops when the client cancels the
+// request or the tool's timeout passes
+func (s *MCPServer) withDeadline(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, release := s.calls.track(ctx, request)
+		defer release()
+
+		timeout := s.defaultToolTimeout
+		if toolTimeout, ok := s.toolTimeouts[name]; ok {
+			timeout = toolTimeout
+		}
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// withMetrics wraps a tool handler to record its outcome and duration. It
+// runs inside withDeadline so timeouts and cancellations can be told apart
+// from errors.
+func (s *MCPServer) withMetrics(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+
+		outcome := metrics.OutcomeSuccess
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			outcome = metrics.OutcomeTimeout
+		case errors.Is(ctx.Err(), context.Canceled):
+			outcome = metrics.OutcomeCancelled
+		case err != nil || (result != nil && result.IsError):
+			outcome = metrics.OutcomeError
+		}
+		s.metrics.ObserveToolCall(name, outcome, time.Since(start))
+
+		return result, err
+	}
+}
+
 // registerTools registers all MCP tools
-func (s *MCPServer) registerTools() {
+func (s *MCPServer) registerTools() error {
 	// Tool 1: compliance_status_overview
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryStatus, mcp.Tool{
 		Name:        "compliance_status_overview",
 		Description: "Get overall compliance operator health and suite status",
+		Annotations: readOnlyAnnotations("Compliance Status Overview"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"namespace": map[string]interface{}{
 					"type":        "string",
 					"description": "Namespace where compliance operator is installed",
@@ -81,12 +330,14 @@ func (s *MCPServer) registerTools() {
 	}, s.handleStatusOverview)
 
 	// Tool 2: compliance_scan_details
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryStatus, mcp.Tool{
 		Name:        "compliance_scan_details",
 		Description: "Get detailed information about a specific compliance scan",
+		Annotations: readOnlyAnnotations("Compliance Scan Details"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"scan_name": map[string]interface{}{
 					"type":        "string",
 					"description": "Name of the ComplianceScan to inspect",
@@ -107,12 +358,14 @@ func (s *MCPServer) registerTools() {
 	}, s.handleScanDetails)
 
 	// Tool 3: compliance_check_results
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryResults, mcp.Tool{
 		Name:        "compliance_check_results",
 		Description: "List check results for a scan with optional filtering",
+		Annotations: readOnlyAnnotations("Compliance Check Results"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"scan_name": map[string]interface{}{
 					"type":        "string",
 					"description": "Scan name to get results for",
@@ -132,18 +385,28 @@ func (s *MCPServer) registerTools() {
 					"description": "Filter by severity",
 					"enum":        []string{"low", "medium", "high", "unknown"},
 				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Continuation cursor returned by a previous call to fetch the next page",
+				},
+				"item": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of a single check result to return in full detail",
+				},
 			},
 			Required: []string{"scan_name"},
 		},
 	}, s.handleCheckResults)
 
 	// Tool 4: compliance_remediations
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryResults, mcp.Tool{
 		Name:        "compliance_remediations",
 		Description: "Get available remediations for failed checks",
+		Annotations: readOnlyAnnotations("Compliance Remediations"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"scan_name": map[string]interface{}{
 					"type":        "string",
 					"description": "Scan name to get remediations for",
@@ -158,17 +421,27 @@ func (s *MCPServer) registerTools() {
 					"description": "Show only applied remediations",
 					"default":     false,
 				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Continuation cursor returned by a previous call to fetch the next page",
+				},
+				"item": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of a single remediation to return in full detail",
+				},
 			},
 		},
 	}, s.handleRemediations)
 
 	// Tool 5: compliance_logs
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryLogs, mcp.Tool{
 		Name:        "compliance_logs",
 		Description: "Fetch and analyze logs from operator and scanner pods",
+		Annotations: readOnlyAnnotations("Compliance Operator Logs"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"pod_type": map[string]interface{}{
 					"type":        "string",
 					"description": "Type of pod to get logs from",
@@ -185,8 +458,8 @@ func (s *MCPServer) registerTools() {
 				},
 				"tail_lines": map[string]interface{}{
 					"type":        "integer",
-					"description": "Number of log lines to fetch",
-					"default":     100,
+					"description": fmt.Sprintf("Number of log lines to fetch (at most %d)", s.logLimits.MaxTailLines),
+					"default":     s.logLimits.DefaultTailLines,
 				},
 				"analyze": map[string]interface{}{
 					"type":        "boolean",
@@ -199,12 +472,14 @@ func (s *MCPServer) registerTools() {
 	}, s.handleLogs)
 
 	// Tool 6: compliance_diagnose
-	s.mcpServer.AddTool(mcp.Tool{
+	s.addTool(CategoryDiagnostics, mcp.Tool{
 		Name:        "compliance_diagnose",
 		Description: "Auto-detect common compliance operator issues",
+		Annotations: readOnlyAnnotations("Diagnose Compliance Operator"),
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
 				"namespace": map[string]interface{}{
 					"type":        "string",
 					"description": "Namespace",
@@ -217,6 +492,215 @@ func (s *MCPServer) registerTools() {
 			},
 		},
 	}, s.handleDiagnose)
+
+	// Tool 7: compliance_clusters
+	s.addTool(CategoryStatus, mcp.Tool{
+		Name:        "compliance_clusters",
+		Description: "List configured clusters with reachability and Compliance Operator version",
+		Annotations: readOnlyAnnotations("Compliance Clusters"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace where compliance operator is installed",
+					"default":     s.namespace,
+				},
+			},
+		},
+	}, s.handleClusters)
+
+	// Tool 8: compliance_fleet_summary
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        "compliance_fleet_summary",
+		Description: "Roll up compliance across every configured cluster: compliance % per cluster and profile, top failing rules and unhealthy operators",
+		Annotations: readOnlyAnnotations("Fleet Compliance Summary"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace where compliance operator is installed",
+					"default":     s.namespace,
+				},
+				"profile": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only profiles whose name contains this, e.g. cis",
+				},
+				"severity_filter": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only list failing rules of this severity",
+					"enum":        []string{"low", "medium", "high", "unknown"},
+				},
+				"top_rules": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of failing rules to list",
+					"default":     defaultTopRules,
+				},
+			},
+		},
+	}, s.handleFleetSummary)
+
+	// Tool 9: compliance_xccdf_export
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        "compliance_xccdf_export",
+		Description: "Export a scan's check results as an XCCDF 1.2 TestResult XML document",
+		Annotations: readOnlyAnnotations("Export XCCDF Results"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
+				"scan_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Scan name to export results for",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace",
+					"default":     s.namespace,
+				},
+			},
+			Required: []string{"scan_name"},
+		},
+	}, s.handleXCCDFExport)
+
+	// Tool 10: compliance_raw_results
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        "compliance_raw_results",
+		Description: "List a scan's raw openscap result files per node from the result ConfigMaps, or return the raw evidence for one rule",
+		Annotations: readOnlyAnnotations("Compliance Raw Results"),
+		InputSchema: rawResultsSchema(s),
+	}, s.handleRawResults)
+
+	// Tool 11: compliance_extract_raw_results
+	s.addTool(CategoryWrite, mcp.Tool{
+		Name:        "compliance_extract_raw_results",
+		Description: "Read a scan's raw ARF reports from its raw results PVC by running a short-lived extractor pod, then list the files per node or return the raw evidence for one rule",
+		Annotations: writeAnnotations("Extract Raw ARF Results"),
+		InputSchema: rawResultsSchema(s),
+	}, s.handleExtractRawResults)
+
+	// Tool 12: compliance_export
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        "compliance_export",
+		Description: "Export a suite's or a scan's results as a document for other tools: OSCAL assessment-results (observations per check, findings per failed rule, control references), XCCDF, SARIF (failed and errored checks), CSV/TSV tables of check results and their remediations, or JUnit XML for CI",
+		Annotations: readOnlyAnnotations("Export Compliance Results"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Document format; xccdf exports a single scan",
+					"enum":        exportFormats,
+				},
+				"suite_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Suite to export, with all its scans",
+				},
+				"scan_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: export only this scan",
+				},
+				"columns": map[string]interface{}{
+					"type":        "array",
+					"description": "csv and tsv: columns to include, in order (default: suite, scan, node_role, rule_id, status, severity, description, remediation_available, remediation_applied)",
+					"items": map[string]interface{}{
+						"type": "string",
+						"enum": compliance.TableColumns,
+					},
+				},
+				"status_filter": map[string]interface{}{
+					"type":        "string",
+					"description": "csv and tsv: only check results with this status",
+					"enum":        []string{"PASS", "FAIL", "MANUAL", "ERROR", "INFO"},
+				},
+				"severity_filter": map[string]interface{}{
+					"type":        "string",
+					"description": "csv and tsv: only check results with this severity",
+					"enum":        []string{"low", "medium", "high", "unknown"},
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace",
+					"default":     s.namespace,
+				},
+			},
+			Required: []string{"format"},
+		},
+	}, s.handleExport)
+
+	// Tool 13: compliance_report
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        reportToolName,
+		Description: "Render a self-contained HTML compliance report for a suite: summary charts, per-scan tables, severity breakdown and failing rules with instructions and remediation status",
+		Annotations: readOnlyAnnotations("Compliance Report"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
+				"suite_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Suite to report on",
+				},
+				"scan_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: report on only this scan",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace",
+					"default":     s.namespace,
+				},
+			},
+		},
+	}, s.handleReport)
+
+	// Tool 14: compliance_controls
+	s.addTool(CategoryResults, mcp.Tool{
+		Name:        "compliance_controls",
+		Description: "Roll check results up to the controls of a framework (NIST 800-53, CIS, PCI-DSS): pass, fail and manual status per control and the rules contributing to each, e.g. to answer \"what is our status on AC-6?\"",
+		Annotations: readOnlyAnnotations("Compliance Controls"),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"cluster": s.clusterProperty(),
+				"framework": map[string]interface{}{
+					"type":        "string",
+					"description": "Control framework as named in the rules' control.compliance.openshift.io annotations, e.g. NIST-800-53, CIS-OCP or PCI-DSS",
+					"default":     compliance.NISTFramework,
+				},
+				"control": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only this control and its enhancements or subsections, e.g. AC-6 also matches AC-6(1)",
+				},
+				"suite_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only the scans of this suite (default: every scan in the namespace)",
+				},
+				"scan_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only this scan",
+				},
+				"status_filter": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only controls with this rolled-up status",
+					"enum":        []string{"PASS", "FAIL", "MANUAL", "ERROR", "INCONSISTENT", "INFO", "NOT-APPLICABLE"},
+				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Continuation cursor returned by a previous call to fetch the next page",
+				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Namespace",
+					"default":     s.namespace,
+				},
+			},
+		},
+	}, s.handleControls)
+
+	return s.checkToolPolicy()
 }
 
 // Tool handlers
@@ -226,12 +710,17 @@ func (s *MCPServer) handleStatusOverview(ctx context.Context, request mcp.CallTo
 	args.Namespace = s.namespace // default
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceStatusOverview(ctx, s.client, s.collector, args)
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceStatusOverview(ctx, client, s.newCollector(client), args)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -242,12 +731,17 @@ func (s *MCPServer) handleScanDetails(ctx context.Context, request mcp.CallToolR
 	args.Namespace = s.namespace
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceScanDetails(ctx, s.client, args)
+	result, err := ComplianceScanDetails(ctx, client, args)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -258,12 +752,17 @@ func (s *MCPServer) handleCheckResults(ctx context.Context, request mcp.CallTool
 	args.Namespace = s.namespace
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceCheckResults(ctx, s.client, args)
+	result, err := ComplianceCheckResults(ctx, client, args, s.responseBudget)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -274,12 +773,17 @@ func (s *MCPServer) handleRemediations(ctx context.Context, request mcp.CallTool
 	args.Namespace = s.namespace
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceRemediations(ctx, s.client, args)
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceRemediations(ctx, client, args, s.responseBudget)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -288,15 +792,19 @@ func (s *MCPServer) handleRemediations(ctx context.Context, request mcp.CallTool
 func (s *MCPServer) handleLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var args LogsArgs
 	args.Namespace = s.namespace
-	args.TailLines = 100 // default
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceLogs(ctx, s.client, args)
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceLogs(ctx, client, args, s.logLimits)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -307,12 +815,180 @@ func (s *MCPServer) handleDiagnose(ctx context.Context, request mcp.CallToolRequ
 	args.Namespace = s.namespace
 
 	if err := parseArgs(request.Params.Arguments, &args); err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceDiagnose(ctx, s.newAnalyzer(client), args)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createTextResult(result), nil
+}
+
+func (s *MCPServer) handleClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args ClustersArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
-	result, err := ComplianceDiagnose(ctx, s.analyzer, args)
+	targets, err := s.clusterTargets(ctx, args.Namespace)
 	if err != nil {
-		return createErrorResult(err), nil
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceClusters(ctx, targets)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createTextResult(result), nil
+}
+
+func (s *MCPServer) handleFleetSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args FleetSummaryArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	rollup, err := s.collectFleet(ctx, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createTextResult(ComplianceFleetSummary(rollup, args)), nil
+}
+
+func (s *MCPServer) handleXCCDFExport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args XCCDFExportArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	summary, resource, err := ComplianceXCCDFExport(ctx, client, s.clusterName(args.Cluster), args)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createResourceResult(summary, resource), nil
+}
+
+func (s *MCPServer) handleExport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args ExportArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	summary, resource, err := ComplianceExport(ctx, client, s.clusterName(args.Cluster), args)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createResourceResult(summary, resource), nil
+}
+
+func (s *MCPServer) handleReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args ReportArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	summary, resource, err := ComplianceReport(ctx, client, s.clusterName(args.Cluster), args)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createResourceResult(summary, resource), nil
+}
+
+func (s *MCPServer) handleControls(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args ControlsArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceControls(ctx, client, s.clusterName(args.Cluster), args, s.responseBudget)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createTextResult(result), nil
+}
+
+func (s *MCPServer) handleRawResults(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args RawResultsArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceRawResults(ctx, client, args, nil)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	return createTextResult(result), nil
+}
+
+func (s *MCPServer) handleExtractRawResults(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args RawResultsArgs
+	args.Namespace = s.namespace
+
+	if err := parseArgs(request.Params.Arguments, &args); err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	client, err := s.clientFor(ctx, args.Cluster, args.Namespace)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
+	}
+
+	result, err := ComplianceRawResults(ctx, client, args, &s.rawResultsExtractor)
+	if err != nil {
+		return s.createErrorResult(ctx, err), nil
 	}
 
 	return createTextResult(result), nil
@@ -346,9 +1022,31 @@ func createTextResult(text string) *mcp.CallToolResult {
 	}
 }
 
-func createErrorResult(err error) *mcp.CallToolResult {
-	log.Printf("Error in tool execution: %v", err)
-	return &mcp.CallToolResult{
+// Kinds of failed calls named in the error result's structured content, in
+// the same field as the reasons a call is rejected
+const (
+	errorNotFound  = "not_found"
+	errorForbidden = "forbidden"
+)
+
+func (s *MCPServer) createErrorResult(ctx context.Context, err error) *mcp.CallToolResult {
+	// Make it clear when a call failed because it ran out of time or was
+	// cancelled rather than because of the cluster
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = fmt.Errorf("%w (stopped because %s)", err, compliance.DescribeContextError(ctxErr))
+	}
+
+	// Point out whose permissions were checked when impersonating
+	if s.impersonate && apierrors.IsForbidden(err) {
+		if user, ok := auth.UserFromContext(ctx); ok {
+			err = fmt.Errorf("%w (request made as %s; ask a cluster administrator for the RBAC permissions this tool needs)", err, user.Name)
+		}
+	}
+
+	s.logger.Log(ctx, compliance.LogLevelError, "Error in tool execution", map[string]interface{}{
+		"error": err.Error(),
+	})
+	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
@@ -357,4 +1055,23 @@ func createErrorResult(err error) *mcp.CallToolResult {
 		},
 		IsError: true,
 	}
+	if kind := errorKind(err); kind != "" {
+		result.StructuredContent = map[string]interface{}{
+			"error":   kind,
+			"message": err.Error(),
+		}
+	}
+	return result
+}
+
+// errorKind classifies Kubernetes API errors that callers may want to act
+// on, such as a suite that doesn't exist, for the structured content
+func errorKind(err error) string {
+	switch {
+	case apierrors.IsNotFound(err):
+		return errorNotFound
+	case apierrors.IsForbidden(err):
+		return errorForbidden
+	}
+	return ""
 }
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// rejectShuttingDown is the reason reported for calls refused while draining
+const rejectShuttingDown = "shutting_down"
+
+// shutdownRetryAfter is the retry hint for calls refused while draining, by
+// which time a replacement server should be ready
+const shutdownRetryAfter = 5 * time.Second
+
+// drainer tracks running tool calls so shutdown can wait for them, and
+// refuses new calls once draining has begun
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	next     uint64
+	active   map[uint64]context.CancelFunc
+	idle     chan struct{}
+}
+
+func newDrainer() *drainer {
+	return &drainer{active: make(map[uint64]context.CancelFunc)}
+}
+
+// enter registers a call. It returns false if the server is draining.
+func (d *drainer) enter(ctx context.Context) (context.Context, func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.draining {
+		return ctx, func() {}, false
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	id := d.next
+	d.next++
+	d.active[id] = cancel
+
+	return ctx, func() {
+		d.mu.Lock()
+		delete(d.active, id)
+		if d.draining && len(d.active) == 0 && d.idle != nil {
+			close(d.idle)
+			d.idle = nil
+		}
+		d.mu.Unlock()
+		cancel()
+	}, true
+}
+
+// drain refuses new calls and waits for running ones to finish. When ctx is
+// done first, the remaining calls are cancelled and an error is returned.
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	if len(d.active) == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	remaining := len(d.active)
+	for _, cancel := range d.active {
+		cancel()
+	}
+	d.mu.Unlock()
+
+	return fmt.Errorf("cancelled %d tool calls still running when the drain deadline passed", remaining)
+}
+
+// isDraining reports whether drain has been called
+func (d *drainer) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// withDrain wraps a tool handler so it is tracked for shutdown and refused
+// once the server is draining
+func (s *MCPServer) withDrain(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, release, ok := s.drainer.enter(ctx)
+		defer release()
+		if !ok {
+			return s.rejectedResult(ctx, name, rejectShuttingDown, "server is shutting down", shutdownRetryAfter), nil
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// Drain stops accepting tool calls and waits until running calls finish or
+// ctx is done, in which case they are cancelled
+func (s *MCPServer) Drain(ctx context.Context) error {
+	return s.drainer.drain(ctx)
+}
+
+// Draining reports whether the server has begun shutting down
+func (s *MCPServer) Draining() bool {
+	return s.drainer.isDraining()
+}
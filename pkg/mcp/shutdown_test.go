@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDrainWithoutCalls(t *testing.T) {
+	d := newDrainer()
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+	if !d.isDraining() {
+		t.Errorf("isDraining() = false after drain")
+	}
+	if _, _, ok := d.enter(context.Background()); ok {
+		t.Errorf("enter() succeeded while draining")
+	}
+}
+
+func TestDrainWaitsForCalls(t *testing.T) {
+	d := newDrainer()
+	_, release, ok := d.enter(context.Background())
+	if !ok {
+		t.Fatal("enter() refused a call before draining")
+	}
+
+	drained := make(chan error)
+	go func() { drained <- d.drain(context.Background()) }()
+
+	select {
+	case err := <-drained:
+		t.Fatalf("drain() returned %v with a call still running", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Errorf("drain() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("drain() did not return after the last call finished")
+	}
+}
+
+func TestDrainDeadlineCancelsCalls(t *testing.T) {
+	d := newDrainer()
+	callCtx, release, _ := d.enter(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := d.drain(ctx)
+	if err == nil || !strings.Contains(err.Error(), "cancelled 1 tool calls") {
+		t.Errorf("drain() error = %v, want one cancelled call", err)
+	}
+	if !errors.Is(callCtx.Err(), context.Canceled) {
+		t.Errorf("running call context error = %v, want context.Canceled", callCtx.Err())
+	}
+}
+
+func TestWithDrain(t *testing.T) {
+	s := newTestServer()
+	s.drainer = newDrainer()
+	handler := s.withDrain("compliance_suite_status", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("call before draining = %+v, %v, want success", result, err)
+	}
+
+	if err := s.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if !s.Draining() {
+		t.Errorf("Draining() = false after Drain")
+	}
+
+	result, err = handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("call while draining error = %v", err)
+	}
+	assertRejected(t, result, rejectShuttingDown)
+}
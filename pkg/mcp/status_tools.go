@@ -10,14 +10,16 @@ import (
 
 // StatusOverviewArgs holds arguments for compliance_status_overview tool
 type StatusOverviewArgs struct {
+	Cluster   string  `json:"cluster,omitempty"`
 	Namespace string  `json:"namespace"`
 	SuiteName *string `json:"suite_name,omitempty"`
 }
 
 // ScanDetailsArgs holds arguments for compliance_scan_details tool
 type ScanDetailsArgs struct {
-	ScanName             string `json:"scan_name"`
-	Namespace            string `json:"namespace"`
+	Cluster             string `json:"cluster,omitempty"`
+	ScanName            string `json:"scan_name"`
+	Namespace           string `json:"namespace"`
 	IncludeCheckResults bool   `json:"include_check_results"`
 }
 
@@ -33,9 +35,13 @@ func ComplianceStatusOverview(ctx context.Context, client *compliance.Compliance
 		return "", fmt.Errorf("failed to collect data: %w", err)
 	}
 
+	output.WriteString(compliance.FormatPartialResult(operatorStatus.Partial))
+
 	// Operator health
 	output.WriteString("## Operator Health\n\n")
-	if operatorStatus.OperatorStatus.IsHealthy {
+	if operatorStatus.Partial != nil && len(operatorStatus.OperatorStatus.OperatorPods) == 0 && len(operatorStatus.OperatorStatus.Issues) == 0 {
+		output.WriteString("⚠️ **Status:** Unknown (not collected)\n")
+	} else if operatorStatus.OperatorStatus.IsHealthy {
 		output.WriteString("✅ **Status:** Healthy\n")
 	} else {
 		output.WriteString("❌ **Status:** Unhealthy\n")
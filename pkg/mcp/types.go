@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/xiyuan/compliance-mcp/pkg/compliance"
@@ -87,19 +88,103 @@ func FormatCheckResults(results []compliance.ComplianceCheckResult) string {
 	}
 
 	for i, result := range results {
-		statusIcon := getStatusIcon(result.Status)
-		severityBadge := getSeverityBadge(result.Severity)
+		output.WriteString(formatCheckResultItem(i, result))
+	}
+
+	return output.String()
+}
+
+// FormatCheckResultsSummary formats the header of a paginated check result
+// listing with counts by status and severity
+func FormatCheckResultsSummary(results []compliance.ComplianceCheckResult) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("# Check Results (%d)\n\n", len(results)))
 
-		output.WriteString(fmt.Sprintf("## %d. %s %s %s\n\n", i+1, result.Name, statusIcon, severityBadge))
+	if len(results) == 0 {
+		output.WriteString("No check results found.\n")
+		return output.String()
+	}
 
-		if result.Description != "" {
-			output.WriteString(fmt.Sprintf("**Description:** %s\n\n", result.Description))
+	counts := compliance.GetCheckCounts(results)
+	output.WriteString(fmt.Sprintf("**By Status:** Pass: %d, Fail: %d, Manual: %d, Error: %d, Info: %d\n",
+		counts.Pass, counts.Fail, counts.Manual, counts.Error, counts.Info))
+
+	severities := make(map[string]int)
+	for _, result := range results {
+		severity := strings.ToLower(result.Severity)
+		if severity == "" {
+			severity = "unknown"
 		}
+		severities[severity]++
+	}
 
-		if result.Instructions != "" && result.Status == compliance.CheckFail {
-			output.WriteString(fmt.Sprintf("**Remediation Instructions:**\n%s\n\n", result.Instructions))
+	var severityCounts []string
+	for _, severity := range []string{"high", "medium", "low", "unknown"} {
+		if count, ok := severities[severity]; ok {
+			severityCounts = append(severityCounts, fmt.Sprintf("%s: %d", severity, count))
+			delete(severities, severity)
 		}
 	}
+	others := make([]string, 0, len(severities))
+	for severity := range severities {
+		others = append(others, severity)
+	}
+	sort.Strings(others)
+	for _, severity := range others {
+		severityCounts = append(severityCounts, fmt.Sprintf("%s: %d", severity, severities[severity]))
+	}
+	output.WriteString(fmt.Sprintf("**By Severity:** %s\n\n", strings.Join(severityCounts, ", ")))
+
+	return output.String()
+}
+
+// FormatCheckResultDetail formats the full detail of a single check result
+func FormatCheckResultDetail(result compliance.ComplianceCheckResult) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("# %s %s %s\n\n", result.Name, getStatusIcon(result.Status), getSeverityBadge(result.Severity)))
+	output.WriteString(fmt.Sprintf("**Status:** %s\n", result.Status))
+
+	if result.ID != "" {
+		output.WriteString(fmt.Sprintf("**Rule ID:** %s\n", result.ID))
+	}
+
+	if result.Severity != "" {
+		output.WriteString(fmt.Sprintf("**Severity:** %s\n", result.Severity))
+	}
+
+	if result.Description != "" {
+		output.WriteString(fmt.Sprintf("\n**Description:** %s\n", result.Description))
+	}
+
+	if result.Rationale != "" {
+		output.WriteString(fmt.Sprintf("\n**Rationale:** %s\n", result.Rationale))
+	}
+
+	if result.Instructions != "" {
+		output.WriteString(fmt.Sprintf("\n**Instructions:**\n%s\n", result.Instructions))
+	}
+
+	return output.String()
+}
+
+// formatCheckResultItem formats one entry of a check result listing
+func formatCheckResultItem(i int, result compliance.ComplianceCheckResult) string {
+	var output strings.Builder
+
+	statusIcon := getStatusIcon(result.Status)
+	severityBadge := getSeverityBadge(result.Severity)
+
+	output.WriteString(fmt.Sprintf("## %d. %s %s %s\n\n", i+1, result.Name, statusIcon, severityBadge))
+
+	if result.Description != "" {
+		output.WriteString(fmt.Sprintf("**Description:** %s\n\n", result.Description))
+	}
+
+	if result.Instructions != "" && result.Status == compliance.CheckFail {
+		output.WriteString(fmt.Sprintf("**Remediation Instructions:**\n%s\n\n", result.Instructions))
+	}
 
 	return output.String()
 }
@@ -108,6 +193,19 @@ func FormatCheckResults(results []compliance.ComplianceCheckResult) string {
 func FormatRemediations(remediations []compliance.ComplianceRemediation) string {
 	var output strings.Builder
 
+	output.WriteString(FormatRemediationsSummary(remediations))
+
+	for i, rem := range remediations {
+		output.WriteString(formatRemediationItem(i, rem))
+	}
+
+	return output.String()
+}
+
+// FormatRemediationsSummary formats the header of a remediation listing
+func FormatRemediationsSummary(remediations []compliance.ComplianceRemediation) string {
+	var output strings.Builder
+
 	output.WriteString(fmt.Sprintf("# Remediations (%d)\n\n", len(remediations)))
 
 	if len(remediations) == 0 {
@@ -124,23 +222,28 @@ func FormatRemediations(remediations []compliance.ComplianceRemediation) string
 
 	output.WriteString(fmt.Sprintf("**Applied:** %d / %d\n\n", appliedCount, len(remediations)))
 
-	for i, rem := range remediations {
-		applied := "❌"
-		if rem.Spec.Apply {
-			applied = "✅"
-		}
+	return output.String()
+}
 
-		output.WriteString(fmt.Sprintf("## %d. %s %s\n\n", i+1, rem.Name, applied))
-		output.WriteString(fmt.Sprintf("**Application State:** %s\n", rem.Status.ApplicationState))
+// formatRemediationItem formats one entry of a remediation listing
+func formatRemediationItem(i int, rem compliance.ComplianceRemediation) string {
+	var output strings.Builder
 
-		// Extract remediation type from labels or annotations
-		if remType, ok := rem.Labels["compliance.openshift.io/remediation-type"]; ok {
-			output.WriteString(fmt.Sprintf("**Type:** %s\n", remType))
-		}
+	applied := "❌"
+	if rem.Spec.Apply {
+		applied = "✅"
+	}
+
+	output.WriteString(fmt.Sprintf("## %d. %s %s\n\n", i+1, rem.Name, applied))
+	output.WriteString(fmt.Sprintf("**Application State:** %s\n", rem.Status.ApplicationState))
 
-		output.WriteString("\n")
+	// Extract remediation type from labels or annotations
+	if remType, ok := rem.Labels["compliance.openshift.io/remediation-type"]; ok {
+		output.WriteString(fmt.Sprintf("**Type:** %s\n", remType))
 	}
 
+	output.WriteString("\n")
+
 	return output.String()
 }
 
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// metricNamespace prefixes every metric this server exports
+const metricNamespace = "compliance_mcp"
+
+// Tool call outcomes
+const (
+	OutcomeSuccess   = "success"
+	OutcomeError     = "error"
+	OutcomeTimeout   = "timeout"
+	OutcomeCancelled = "cancelled"
+)
+
+// Metrics holds the server's Prometheus metrics and the registry they are
+// exported from
+type Metrics struct {
+	registry *prometheus.Registry
+
+	toolCalls    *prometheus.CounterVec
+	toolDuration *prometheus.HistogramVec
+	toolRejected *prometheus.CounterVec
+	apiCalls     *prometheus.CounterVec
+	apiErrors    *prometheus.CounterVec
+	apiDuration  *prometheus.HistogramVec
+}
+
+// New creates the server metrics in a new registry, along with the standard
+// Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "tool_calls_total",
+			Help:      "MCP tool calls by tool and outcome (success, error, timeout, cancelled).",
+		}, []string{"tool", "outcome"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "tool_call_duration_seconds",
+			Help:      "Time taken by MCP tool calls.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}, []string{"tool"}),
+		toolRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "tool_calls_rejected_total",
+			Help:      "MCP tool calls rejected before running by tool and reason (rate_limited, busy, shutting_down, denied).",
+		}, []string{"tool", "reason"}),
+		apiCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "kubernetes_api_calls_total",
+			Help:      "Kubernetes API calls made by the server by verb and resource.",
+		}, []string{"verb", "resource"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "kubernetes_api_errors_total",
+			Help:      "Failed Kubernetes API calls by verb, resource and Kubernetes status reason.",
+		}, []string{"verb", "resource", "reason"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "kubernetes_api_call_duration_seconds",
+			Help:      "Time taken by Kubernetes API calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"verb", "resource"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.toolCalls,
+		m.toolDuration,
+		m.toolRejected,
+		m.apiCalls,
+		m.apiErrors,
+		m.apiDuration,
+	)
+
+	return m
+}
+
+// Register adds a collector, such as a PostureCollector, to the registry
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveToolCall records a finished tool call
+func (m *Metrics) ObserveToolCall(tool, outcome string, duration time.Duration) {
+	m.toolCalls.WithLabelValues(tool, outcome).Inc()
+	m.toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ObserveRejectedToolCall records a tool call that was refused before it ran
+func (m *Metrics) ObserveRejectedToolCall(tool, reason string) {
+	m.toolRejected.WithLabelValues(tool, reason).Inc()
+}
+
+// ObserveAPICall implements compliance.APIObserver
+func (m *Metrics) ObserveAPICall(verb, resource string, duration time.Duration, err error) {
+	m.apiCalls.WithLabelValues(verb, resource).Inc()
+	m.apiDuration.WithLabelValues(verb, resource).Observe(duration.Seconds())
+
+	if err != nil {
+		reason := string(apierrors.ReasonForError(err))
+		if reason == "" {
+			reason = "Unknown"
+		}
+		m.apiErrors.WithLabelValues(verb, resource, reason).Inc()
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestObserveToolCall(t *testing.T) {
+	m := New()
+	m.ObserveToolCall("compliance_suite_status", OutcomeSuccess, time.Second)
+	m.ObserveToolCall("compliance_suite_status", OutcomeSuccess, time.Second)
+	m.ObserveToolCall("compliance_diagnose", OutcomeTimeout, time.Minute)
+
+	want := `
+# HELP compliance_mcp_tool_calls_total MCP tool calls by tool and outcome (success, error, timeout, cancelled).
+# TYPE compliance_mcp_tool_calls_total counter
+compliance_mcp_tool_calls_total{outcome="success",tool="compliance_suite_status"} 2
+compliance_mcp_tool_calls_total{outcome="timeout",tool="compliance_diagnose"} 1
+`
+	if err := testutil.CollectAndCompare(m.toolCalls, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(m.toolDuration); got != 2 {
+		t.Errorf("got %d tool duration series, want 2", got)
+	}
+}
+
+func TestObserveAPICall(t *testing.T) {
+	m := New()
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "compliancesuites"}, "cis")
+
+	m.ObserveAPICall("list", "compliancescans", time.Second, nil)
+	m.ObserveAPICall("get", "compliancesuites", time.Second, notFound)
+	m.ObserveAPICall("get", "compliancesuites", time.Second, errors.New("connection refused"))
+
+	want := `
+# HELP compliance_mcp_kubernetes_api_errors_total Failed Kubernetes API calls by verb, resource and Kubernetes status reason.
+# TYPE compliance_mcp_kubernetes_api_errors_total counter
+compliance_mcp_kubernetes_api_errors_total{reason="NotFound",resource="compliancesuites",verb="get"} 1
+compliance_mcp_kubernetes_api_errors_total{reason="Unknown",resource="compliancesuites",verb="get"} 1
+`
+	if err := testutil.CollectAndCompare(m.apiErrors, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.ToFloat64(m.apiCalls.WithLabelValues("get", "compliancesuites")); got != 2 {
+		t.Errorf("got %v get calls, want 2", got)
+	}
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+)
+
+var (
+	checkResultsDesc = prometheus.NewDesc(
+		metricNamespace+"_check_results",
+		"ComplianceCheckResults by suite, scan, status and severity.",
+		[]string{"suite", "scan", "status", "severity"}, nil)
+	scanCompliancePercentDesc = prometheus.NewDesc(
+		metricNamespace+"_scan_compliance_percent",
+		"Passing automated checks as a percentage of passing and failing checks.",
+		[]string{"suite", "scan"}, nil)
+	suiteResultDesc = prometheus.NewDesc(
+		metricNamespace+"_suite_result",
+		"Current result of each ComplianceSuite; the series with value 1 is the current result.",
+		[]string{"suite", "phase", "result"}, nil)
+	remediationsDesc = prometheus.NewDesc(
+		metricNamespace+"_remediations",
+		"ComplianceRemediations by suite, scan and state (applied or pending).",
+		[]string{"suite", "scan", "state"}, nil)
+	operatorPodRestartsDesc = prometheus.NewDesc(
+		metricNamespace+"_operator_pod_restarts",
+		"Container restarts of each Compliance Operator pod.",
+		[]string{"pod"}, nil)
+	operatorHealthyDesc = prometheus.NewDesc(
+		metricNamespace+"_operator_healthy",
+		"1 if the Compliance Operator pods are healthy, 0 otherwise.",
+		nil, nil)
+	postureUpDesc = prometheus.NewDesc(
+		metricNamespace+"_posture_collection_success",
+		"1 if the last collection of compliance data succeeded completely, 0 otherwise.",
+		nil, nil)
+	postureTimestampDesc = prometheus.NewDesc(
+		metricNamespace+"_posture_collection_timestamp_seconds",
+		"Unix time of the compliance data the posture metrics are derived from.",
+		nil, nil)
+)
+
+// PostureCollector exports compliance posture gauges derived from Collector
+// data. Run collects the data in the background once per interval and
+// scrapes serve the latest collection, so a scrape never waits on the
+// Kubernetes API and frequent scrapes don't list every scan's results.
+type PostureCollector struct {
+	collector *compliance.Collector
+	interval  time.Duration
+	timeout   time.Duration
+
+	mu        sync.Mutex
+	data      *compliance.ComplianceData
+	succeeded bool
+}
+
+// NewPostureCollector creates a posture collector. Each collection is
+// bounded by timeout (zero means no limit). Call Run to start collecting.
+func NewPostureCollector(collector *compliance.Collector, interval, timeout time.Duration) *PostureCollector {
+	return &PostureCollector{
+		collector: collector,
+		interval:  interval,
+		timeout:   timeout,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (p *PostureCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- checkResultsDesc
+	ch <- scanCompliancePercentDesc
+	ch <- suiteResultDesc
+	ch <- remediationsDesc
+	ch <- operatorPodRestartsDesc
+	ch <- operatorHealthyDesc
+	ch <- postureUpDesc
+	ch <- postureTimestampDesc
+}
+
+// Collect implements prometheus.Collector
+func (p *PostureCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	data, succeeded := p.data, p.succeeded
+	p.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(postureUpDesc, prometheus.GaugeValue, boolValue(succeeded))
+	if data == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(postureTimestampDesc, prometheus.GaugeValue, float64(data.Timestamp.Unix()))
+
+	for _, suite := range data.Suites {
+		ch <- prometheus.MustNewConstMetric(suiteResultDesc, prometheus.GaugeValue, 1,
+			suite.Name, string(suite.Status.Phase), string(suite.Status.Result))
+	}
+
+	for scanName, scan := range data.Scans {
+		suiteName := scan.Labels[compliance.SuiteLabel]
+
+		if results, ok := data.CheckResults[scanName]; ok {
+			counts := make(map[[2]string]int)
+			for _, result := range results {
+				severity := strings.ToLower(result.Severity)
+				if severity == "" {
+					severity = "unknown"
+				}
+				counts[[2]string{string(result.Status), severity}]++
+			}
+			for key, count := range counts {
+				ch <- prometheus.MustNewConstMetric(checkResultsDesc, prometheus.GaugeValue, float64(count),
+					suiteName, scanName, key[0], key[1])
+			}
+
+			percentage := compliance.CalculateCompliancePercentage(compliance.GetCheckCounts(results))
+			ch <- prometheus.MustNewConstMetric(scanCompliancePercentDesc, prometheus.GaugeValue, percentage,
+				suiteName, scanName)
+		}
+
+		if remediations, ok := data.Remediations[scanName]; ok {
+			applied := 0
+			for _, rem := range remediations {
+				if rem.Spec.Apply {
+					applied++
+				}
+			}
+			ch <- prometheus.MustNewConstMetric(remediationsDesc, prometheus.GaugeValue, float64(applied),
+				suiteName, scanName, "applied")
+			ch <- prometheus.MustNewConstMetric(remediationsDesc, prometheus.GaugeValue, float64(len(remediations)-applied),
+				suiteName, scanName, "pending")
+		}
+	}
+
+	if data.Partial == nil || len(data.OperatorStatus.OperatorPods) > 0 {
+		ch <- prometheus.MustNewConstMetric(operatorHealthyDesc, prometheus.GaugeValue, boolValue(data.OperatorStatus.IsHealthy))
+	}
+	for _, pod := range data.OperatorStatus.OperatorPods {
+		ch <- prometheus.MustNewConstMetric(operatorPodRestartsDesc, prometheus.GaugeValue, float64(pod.Restarts), pod.Name)
+	}
+}
+
+// Run collects compliance data immediately and then once per interval
+// until ctx is done
+func (p *PostureCollector) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh collects compliance data once. On failure the previous data is
+// kept and reported as stale through the collection success gauge.
+func (p *PostureCollector) refresh(ctx context.Context) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	data, err := p.collector.CollectAllData(ctx)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// Shutting down
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to collect compliance posture metrics: %v", err)
+		p.succeeded = false
+		return
+	}
+
+	p.data = data
+	p.succeeded = data.Partial == nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
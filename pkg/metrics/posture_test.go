@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/xiyuan/compliance-mcp/pkg/compliance"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestPostureCollect(t *testing.T) {
+	scanLabels := map[string]string{compliance.SuiteLabel: "cis-compliance"}
+	data := &compliance.ComplianceData{
+		Suites: []compliance.ComplianceSuite{{
+			ObjectMeta: metav1.ObjectMeta{Name: "cis-compliance"},
+			Status:     compliance.ComplianceSuiteStatus{Phase: compliance.PhaseDone, Result: compliance.ResultNonCompliant},
+		}},
+		Scans: map[string]compliance.ComplianceScan{
+			"ocp4-cis": {ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis", Labels: scanLabels}},
+		},
+		CheckResults: map[string][]compliance.ComplianceCheckResult{
+			"ocp4-cis": {
+				{Status: compliance.CheckPass, Severity: "high"},
+				{Status: compliance.CheckPass, Severity: "HIGH"},
+				{Status: compliance.CheckFail, Severity: "medium"},
+				{Status: compliance.CheckManual},
+			},
+		},
+		Remediations: map[string][]compliance.ComplianceRemediation{
+			"ocp4-cis": {
+				{Spec: compliance.ComplianceRemediationSpec{Apply: true}},
+				{},
+				{},
+			},
+		},
+		OperatorStatus: compliance.OperatorHealthStatus{
+			IsHealthy:    true,
+			OperatorPods: []compliance.PodStatus{{Name: "compliance-operator-0", Restarts: 2}},
+		},
+		Timestamp: time.Unix(1790000000, 0),
+	}
+
+	// Scrapes serve the last collection without listing anything
+	collector := &PostureCollector{
+		data:      data,
+		succeeded: true,
+	}
+
+	want := `
+# HELP compliance_mcp_check_results ComplianceCheckResults by suite, scan, status and severity.
+# TYPE compliance_mcp_check_results gauge
+compliance_mcp_check_results{scan="ocp4-cis",severity="high",status="PASS",suite="cis-compliance"} 2
+compliance_mcp_check_results{scan="ocp4-cis",severity="medium",status="FAIL",suite="cis-compliance"} 1
+compliance_mcp_check_results{scan="ocp4-cis",severity="unknown",status="MANUAL",suite="cis-compliance"} 1
+# HELP compliance_mcp_operator_healthy 1 if the Compliance Operator pods are healthy, 0 otherwise.
+# TYPE compliance_mcp_operator_healthy gauge
+compliance_mcp_operator_healthy 1
+# HELP compliance_mcp_operator_pod_restarts Container restarts of each Compliance Operator pod.
+# TYPE compliance_mcp_operator_pod_restarts gauge
+compliance_mcp_operator_pod_restarts{pod="compliance-operator-0"} 2
+# HELP compliance_mcp_posture_collection_success 1 if the last collection of compliance data succeeded completely, 0 otherwise.
+# TYPE compliance_mcp_posture_collection_success gauge
+compliance_mcp_posture_collection_success 1
+# HELP compliance_mcp_posture_collection_timestamp_seconds Unix time of the compliance data the posture metrics are derived from.
+# TYPE compliance_mcp_posture_collection_timestamp_seconds gauge
+compliance_mcp_posture_collection_timestamp_seconds 1.79e+09
+# HELP compliance_mcp_remediations ComplianceRemediations by suite, scan and state (applied or pending).
+# TYPE compliance_mcp_remediations gauge
+compliance_mcp_remediations{scan="ocp4-cis",state="applied",suite="cis-compliance"} 1
+compliance_mcp_remediations{scan="ocp4-cis",state="pending",suite="cis-compliance"} 2
+# HELP compliance_mcp_scan_compliance_percent Passing automated checks as a percentage of passing and failing checks.
+# TYPE compliance_mcp_scan_compliance_percent gauge
+compliance_mcp_scan_compliance_percent{scan="ocp4-cis",suite="cis-compliance"} 66.66666666666666
+# HELP compliance_mcp_suite_result Current result of each ComplianceSuite; the series with value 1 is the current result.
+# TYPE compliance_mcp_suite_result gauge
+compliance_mcp_suite_result{phase="DONE",result="NON-COMPLIANT",suite="cis-compliance"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostureCollectPartial(t *testing.T) {
+	// Operator health is unknown when collection stopped before the
+	// operator pods were listed
+	collector := &PostureCollector{
+		data: &compliance.ComplianceData{
+			Timestamp: time.Unix(1790000000, 0),
+			Partial:   &compliance.PartialResult{},
+		},
+	}
+
+	if got := testutil.CollectAndCount(collector, metricNamespace+"_operator_healthy"); got != 0 {
+		t.Errorf("got %d operator health series, want none", got)
+	}
+	if got := testutil.CollectAndCount(collector, metricNamespace+"_posture_collection_success"); got != 1 {
+		t.Errorf("got %d collection success series, want 1", got)
+	}
+}
+
+func TestPostureRefreshFailure(t *testing.T) {
+	// Every list fails, so the refresh keeps the previous collection
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+
+	client, err := compliance.NewComplianceClientForConfig(&rest.Config{Host: api.URL}, "openshift-compliance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := &compliance.ComplianceData{Timestamp: time.Unix(1790000000, 0)}
+	collector := NewPostureCollector(compliance.NewCollector(client), time.Hour, time.Second)
+	collector.data = previous
+	collector.succeeded = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		collector.Run(ctx)
+		close(done)
+	}()
+
+	// Run collects once straight away
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		collector.mu.Lock()
+		succeeded := collector.succeeded
+		collector.mu.Unlock()
+		if !succeeded {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if collector.data != previous {
+		t.Error("failed refresh replaced the previous data")
+	}
+	want := `
+# HELP compliance_mcp_posture_collection_success 1 if the last collection of compliance data succeeded completely, 0 otherwise.
+# TYPE compliance_mcp_posture_collection_success gauge
+compliance_mcp_posture_collection_success 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want), metricNamespace+"_posture_collection_success"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,318 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key to
+// dir, returning their paths
+func writeCert(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "server")
+	badCA := filepath.Join(dir, "bad-ca.crt")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		cfg            Config
+		wantMinVersion uint16
+		wantClientAuth tls.ClientAuthType
+		wantErr        string
+	}{
+		{
+			name:           "defaults",
+			cfg:            Config{CertFile: certFile, KeyFile: keyFile},
+			wantMinVersion: tls.VersionTLS12,
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:           "TLS 1.3",
+			cfg:            Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"},
+			wantMinVersion: tls.VersionTLS13,
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:           "client CA admits clients without certificates to the handshake",
+			cfg:            Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile},
+			wantMinVersion: tls.VersionTLS12,
+			wantClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:           "client CA verifies certificates if given",
+			cfg:            Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, ClientAuth: ClientAuthVerifyIfGiven},
+			wantMinVersion: tls.VersionTLS12,
+			wantClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:    "missing key",
+			cfg:     Config{CertFile: certFile},
+			wantErr: "both a certificate and a key file are required",
+		},
+		{
+			name:    "TLS 1.1",
+			cfg:     Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.1"},
+			wantErr: `unsupported minimum TLS version "1.1"`,
+		},
+		{
+			name:    "unknown client auth mode",
+			cfg:     Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, ClientAuth: "optional"},
+			wantErr: `invalid client auth mode "optional"`,
+		},
+		{
+			name:    "client CA without certificates",
+			cfg:     Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: badCA},
+			wantErr: "no certificates found in client CA bundle",
+		},
+		{
+			name:    "certificate and key mismatch",
+			cfg:     Config{CertFile: keyFile, KeyFile: certFile},
+			wantErr: "failed to load certificate and key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := NewServerTLSConfig(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewServerTLSConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewServerTLSConfig() error = %v", err)
+			}
+			if tlsConfig.MinVersion != tt.wantMinVersion {
+				t.Errorf("MinVersion = %x, want %x", tlsConfig.MinVersion, tt.wantMinVersion)
+			}
+			if tlsConfig.ClientAuth != tt.wantClientAuth {
+				t.Errorf("ClientAuth = %v, want %v", tlsConfig.ClientAuth, tt.wantClientAuth)
+			}
+		})
+	}
+}
+
+func TestClientCertRequired(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{name: "no client CA", cfg: Config{}, want: false},
+		{name: "default mode", cfg: Config{ClientCAFile: "ca.crt"}, want: true},
+		{name: "require", cfg: Config{ClientCAFile: "ca.crt", ClientAuth: ClientAuthRequire}, want: true},
+		{name: "verify if given", cfg: Config{ClientCAFile: "ca.crt", ClientAuth: ClientAuthVerifyIfGiven}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.ClientCertRequired(); got != tt.want {
+			t.Errorf("%s: ClientCertRequired() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRequireVerifiedClientCert(t *testing.T) {
+	handler := RequireVerifiedClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name  string
+		state *tls.ConnectionState
+		want  int
+	}{
+		{name: "plain HTTP", state: nil, want: http.StatusUnauthorized},
+		{name: "no client certificate", state: &tls.ConnectionState{}, want: http.StatusUnauthorized},
+		{name: "verified client certificate", state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}, want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+			request.TLS = tt.state
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "first")
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	if name := servedName(t, reloader); name != "first" {
+		t.Fatalf("serving %q, want first", name)
+	}
+
+	// Rotate the files, moving their modification time on so the change is
+	// seen even on file systems with coarse timestamps
+	writeCert(t, dir, "second")
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Files are only checked once per interval
+	if name := servedName(t, reloader); name != "first" {
+		t.Errorf("serving %q before the check interval, want first", name)
+	}
+
+	reloader.lastCheck = time.Time{}
+	if name := servedName(t, reloader); name != "second" {
+		t.Errorf("serving %q after rotation, want second", name)
+	}
+
+	// A half-written rotation keeps the previous certificate
+	if err := os.WriteFile(keyFile, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	latest := later.Add(time.Minute)
+	if err := os.Chtimes(keyFile, latest, latest); err != nil {
+		t.Fatal(err)
+	}
+	reloader.lastCheck = time.Time{}
+	if name := servedName(t, reloader); name != "second" {
+		t.Errorf("serving %q after a broken rotation, want second", name)
+	}
+}
+
+// servedName returns the common name of the certificate the reloader serves
+func servedName(t *testing.T, reloader *CertReloader) string {
+	t.Helper()
+
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestCAReloader(t *testing.T) {
+	dir := t.TempDir()
+	first, _ := writeCert(t, dir, "first")
+	firstCert := readCert(t, first)
+
+	reloader, err := NewCAReloader(first)
+	if err != nil {
+		t.Fatalf("NewCAReloader() error = %v", err)
+	}
+	if !trusts(reloader.Pool(), firstCert) {
+		t.Fatalf("bundle does not trust the first CA")
+	}
+
+	second, _ := writeCert(t, dir, "second")
+	secondCert := readCert(t, second)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(second, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundle is only checked once per interval
+	if trusts(reloader.Pool(), secondCert) {
+		t.Errorf("bundle trusts the second CA before the check interval")
+	}
+
+	reloader.lastCheck = time.Time{}
+	if pool := reloader.Pool(); !trusts(pool, secondCert) || trusts(pool, firstCert) {
+		t.Errorf("bundle does not trust only the second CA after rotation")
+	}
+
+	// A half-written rotation keeps the previous bundle
+	if err := os.WriteFile(second, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	latest := later.Add(time.Minute)
+	if err := os.Chtimes(second, latest, latest); err != nil {
+		t.Fatal(err)
+	}
+	reloader.lastCheck = time.Time{}
+	if !trusts(reloader.Pool(), secondCert) {
+		t.Errorf("bundle does not trust the second CA after a broken rotation")
+	}
+}
+
+// readCert parses the certificate in file
+func readCert(t *testing.T, file string) *x509.Certificate {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// trusts reports whether pool verifies cert
+func trusts(pool *x509.CertPool, cert *x509.Certificate) bool {
+	_, err := cert.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	return err == nil
+}
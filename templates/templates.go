@@ -0,0 +1,10 @@
+// Package templates embeds the HTML templates the server renders, so the
+// binary needs no files at runtime
+package templates
+
+import "embed"
+
+// FS holds every template in this directory
+//
+//go:embed *.html
+var FS embed.FS